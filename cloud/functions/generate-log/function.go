@@ -84,7 +84,10 @@ func helloStorage(ctx context.Context, e event.Event) error {
 	defer outputFile.Close()
 
 	//process file
-  gameLogCmd := NewGameLog(inputFile.Name(), outputFile.Name())
+  gameLogCmd, err := sim.NewGameLog(inputFile.Name(), outputFile.Name(), sim.GameLogOptions{})
+  if err != nil {
+    return fmt.Errorf("sim.NewGameLog: %v", err)
+  }
 		gameLogCmd.Execute()
     
     if err = fileIsEmpty(inputFile); err != nil {
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"runtime"
 )
 
 type FlagSetVars struct {
@@ -11,25 +12,78 @@ type FlagSetVars struct {
 	simPath      string
 	resultPath   string
 	logPath      string
+	layoutPath   string
+	discover     bool
+	force        bool
+	recalc       bool
+	format       string
+	// parse_log has other formats, the shared format defaults to text
+	parseFormat  string
 	hour         int
+	fromHour     int
+	toHour       int
+	continueHour int
+	outDir       string
+	workers      int
+	watch        bool
+	outPath      string
+	clearBy      string
+	clear        bool
+	planPath     string
+}
+
+// Commands builds the flag sets of all commands, they share the variables
+func (c *FlagSetVars) Commands() map[string]*flag.FlagSet {
+	return map[string]*flag.FlagSet{
+		GenerateLogCmd:  c.GenerateLogCmd(),
+		ParseLogCmd:     c.ParseLogCmd(),
+		CheckVersionCmd: c.CheckVersionCmd(),
+		ValidateCmd:     c.ValidateCmd(),
+		DiffCmd:         c.DiffCmd(),
+		ClearInputsCmd:  c.ClearInputsCmd(),
+		Log2SimCmd:      c.Log2SimCmd(),
+		ApplyPlanCmd:    c.ApplyPlanCmd(),
+		StatsCmd:        c.StatsCmd(),
+		RoundTripCmd:    c.RoundTripCmd(),
+	}
 }
 
 const (
-	GenerateLogCmd = "generate_log"
-	ParseLogCmd    = "parse_log"
+	GenerateLogCmd  = "generate_log"
+	ParseLogCmd     = "parse_log"
+	CheckVersionCmd = "check-version"
+	ValidateCmd     = "validate"
+	DiffCmd         = "diff"
+	ClearInputsCmd  = "clear-inputs"
+	Log2SimCmd      = "log2sim"
+	ApplyPlanCmd    = "apply-plan"
+	StatsCmd        = "stats"
+	RoundTripCmd    = "roundtrip"
 )
 
 func (c *FlagSetVars) GenerateLogCmd() *flag.FlagSet {
 	cmd := flag.NewFlagSet(GenerateLogCmd, flag.ExitOnError)
 	// cmd.BoolVar(&c.debugEnabled, "debug", false, "Enable debug logging")
-	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file")
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file or a pattern like 'sims/*.xlsm' to generate many logs")
 	cmd.StringVar(&c.resultPath, "result", "", "Path to the result file \"\" or \"std\" prints to stdout")
-	cmd.IntVar(&c.hour, "hour", 0, "Set current hour")
+	cmd.StringVar(&c.outDir, "outdir", "", "Directory of result files when -sim is a pattern")
+	cmd.IntVar(&c.workers, "workers", runtime.NumCPU(), "Number of sims generated at once with -outdir")
+	cmd.BoolVar(&c.watch, "watch", false, "Regenerate the result file every time the sim is saved")
+	cmd.IntVar(&c.hour, "hour", 0, "Generate only this hour")
+	cmd.IntVar(&c.fromHour, "from", 0, "First generated hour")
+	cmd.IntVar(&c.toHour, "to", 0, "Last generated hour")
+	cmd.IntVar(&c.continueHour, "continue", 0, "Continue a partial import, generate from this hour to the end")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.BoolVar(&c.force, "force", false, "Generate the log even if the sim has validation errors")
+	cmd.BoolVar(&c.recalc, "recalc", false, "Recalculate formulas instead of using values saved by Excel")
+	cmd.StringVar(&c.format, "format", "text", "Result format: text (import log), json or ndjson")
 	cmd.Usage = func() {
 		fmt.Printf("Usage of %s %s:\n", os.Args[0], GenerateLogCmd)
 		cmd.PrintDefaults()
 		fmt.Println("Example:")
-		fmt.Printf("  %s %s -sim sim.xlsm -result sim.txt\n\n", os.Args[0], GenerateLogCmd)
+		fmt.Printf("  %s %s -sim sim.xlsm -result sim.txt\n", os.Args[0], GenerateLogCmd)
+		fmt.Printf("  %s %s -sim 'sims/*.xlsm' -outdir logs\n\n", os.Args[0], GenerateLogCmd)
 	}
 
 	return cmd
@@ -39,12 +93,169 @@ func (c *FlagSetVars) ParseLogCmd() *flag.FlagSet {
 	cmd := flag.NewFlagSet(ParseLogCmd, flag.ExitOnError)
 	// cmd.BoolVar(&c.debugEnabled, "debug", false, "Enable debug logging")
 	cmd.StringVar(&c.logPath, "log", "", "Path to the txt log file")
+	cmd.StringVar(&c.resultPath, "result", "", "Path to the result file \"\" or \"std\" prints to stdout")
+	cmd.StringVar(&c.parseFormat, "format", "json", "Result format: json, yaml or csv (a row for every action of an hour)")
 	cmd.Usage = func() {
 		fmt.Printf("Usage of %s %s:\n", os.Args[0], ParseLogCmd)
 		cmd.PrintDefaults()
 		fmt.Println("\nExample:")
 		fmt.Printf("  %s %s -log sim.txt\n", os.Args[0], ParseLogCmd)
+		fmt.Printf("  %s %s -log sim.txt -result parsed.csv -format csv\n\n", os.Args[0], ParseLogCmd)
+	}
+
+	return cmd
+}
+
+func (c *FlagSetVars) CheckVersionCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(CheckVersionCmd, flag.ExitOnError)
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to a sim layout file to check besides the built-in ones")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s:\n", os.Args[0], CheckVersionCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -sim sim.xlsm\n\n", os.Args[0], CheckVersionCmd)
+	}
+
+	return cmd
+}
+
+func (c *FlagSetVars) ValidateCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(ValidateCmd, flag.ExitOnError)
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file")
+	cmd.StringVar(&c.resultPath, "result", "", "Path to the report file \"\" or \"std\" prints to stdout")
+	cmd.StringVar(&c.format, "format", "text", "Report format: text, json or junit")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.BoolVar(&c.recalc, "recalc", false, "Recalculate formulas instead of using values saved by Excel")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s:\n", os.Args[0], ValidateCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -sim sim.xlsm -format junit -result report.xml\n\n", os.Args[0], ValidateCmd)
+	}
+
+	return cmd
+}
+
+func (c *FlagSetVars) DiffCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(DiffCmd, flag.ExitOnError)
+	cmd.StringVar(&c.resultPath, "result", "", "Path to the report file \"\" or \"std\" prints to stdout")
+	cmd.StringVar(&c.format, "format", "text", "Report format: text or json")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s [options] old.xlsm new.xlsm:\n", os.Args[0], DiffCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -format json last_round.xlsm sim.xlsm\n\n", os.Args[0], DiffCmd)
+	}
+
+	return cmd
+}
+
+func (c *FlagSetVars) ClearInputsCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(ClearInputsCmd, flag.ExitOnError)
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file")
+	cmd.StringVar(&c.outPath, "out", "", "Path to the cleared sim file")
+	cmd.StringVar(&c.clearBy, "by", "style", "Find input cells by their orange fill (style) or by the sim layout (layout)")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s:\n", os.Args[0], ClearInputsCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -sim sim.xlsm -out template.xlsm\n\n", os.Args[0], ClearInputsCmd)
 	}
 
 	return cmd
 }
+
+func (c *FlagSetVars) Log2SimCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(Log2SimCmd, flag.ExitOnError)
+	cmd.StringVar(&c.logPath, "log", "", "Path to the txt import log")
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim template file")
+	cmd.StringVar(&c.outPath, "out", "", "Path to the filled sim file")
+	cmd.BoolVar(&c.clear, "clear", false, "Clear input cells of the template before writing the log")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s:\n", os.Args[0], Log2SimCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -log import.txt -sim template.xlsm -out sim.xlsm\n\n", os.Args[0], Log2SimCmd)
+	}
+
+	return cmd
+}
+
+func (c *FlagSetVars) ApplyPlanCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(ApplyPlanCmd, flag.ExitOnError)
+	cmd.StringVar(&c.planPath, "plan", "", "Path to the TOML build plan")
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file")
+	cmd.StringVar(&c.outPath, "out", "", "Path to the filled sim file")
+	cmd.BoolVar(&c.clear, "clear", false, "Clear input cells of the sim before applying the plan")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s:\n", os.Args[0], ApplyPlanCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -plan build.toml -sim new_round.xlsm -out mine.xlsm\n\n", os.Args[0], ApplyPlanCmd)
+	}
+
+	return cmd
+}
+
+func (c *FlagSetVars) StatsCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(StatsCmd, flag.ExitOnError)
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file")
+	cmd.IntVar(&c.hour, "hour", 0, "Show the dominion at the end of this hour, 0 uses the hour set in the sim")
+	cmd.StringVar(&c.resultPath, "result", "", "Path to the result file \"\" or \"std\" prints to stdout")
+	cmd.StringVar(&c.format, "format", "text", "Result format: text or json")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.BoolVar(&c.recalc, "recalc", false, "Recalculate formulas instead of using values saved by Excel")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s:\n", os.Args[0], StatsCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -sim sim.xlsm -hour 24 -format json\n\n", os.Args[0], StatsCmd)
+	}
+
+	return cmd
+}
+
+func (c *FlagSetVars) RoundTripCmd() *flag.FlagSet {
+	cmd := flag.NewFlagSet(RoundTripCmd, flag.ExitOnError)
+	cmd.StringVar(&c.simPath, "sim", "", "Path to the sim file")
+	cmd.StringVar(&c.resultPath, "result", "", "Path to the report file \"\" or \"std\" prints to stdout")
+	cmd.StringVar(&c.format, "format", "text", "Report format: text or json")
+	cmd.StringVar(&c.layoutPath, "layout", "", "Path to the sim layout file, \"\" uses the built-in one")
+	cmd.BoolVar(&c.discover, "discover", true, "Find sim columns by their header labels")
+	cmd.BoolVar(&c.recalc, "recalc", false, "Recalculate formulas instead of using values saved by Excel")
+	cmd.Usage = func() {
+		fmt.Printf("Usage of %s %s:\n", os.Args[0], RoundTripCmd)
+		cmd.PrintDefaults()
+		fmt.Println("\nExample:")
+		fmt.Printf("  %s %s -sim sim.xlsm\n\n", os.Args[0], RoundTripCmd)
+	}
+
+	return cmd
+}
+
+// hourRange returns the generated hours from -hour, -from/-to and -continue flags
+func (c *FlagSetVars) hourRange() (int, int, error) {
+	switch {
+	case c.hour > 0 && (c.fromHour > 0 || c.toHour > 0 || c.continueHour > 0):
+		return 0, 0, fmt.Errorf("-hour can't be used with -from, -to or -continue")
+	case c.continueHour > 0 && (c.fromHour > 0 || c.toHour > 0):
+		return 0, 0, fmt.Errorf("-continue can't be used with -from or -to")
+	case c.hour > 0:
+		return c.hour, c.hour, nil
+	case c.continueHour > 0:
+		return c.continueHour, 0, nil
+	}
+
+	return c.fromHour, c.toHour, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rxx/od_tools/pkg/sim"
+)
+
+func TestParseLogDefaultFormat(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "sim.txt")
+	if err := os.WriteFile(logPath, []byte("Draftrate changed to 90%.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := &FlagSetVars{}
+	commands := vars.Commands()
+	if err := commands[ParseLogCmd].Parse([]string{"-log", logPath}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if vars.parseFormat != sim.FormatJSON {
+		t.Errorf("parse_log format should be json by default, got %q", vars.parseFormat)
+	}
+	if _, err := sim.NewLogCmd(vars.logPath, vars.resultPath, vars.parseFormat); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
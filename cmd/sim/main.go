@@ -4,7 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
-  "github.com/tamadamas/od_tools/pkg/sim"
+
+	"github.com/rxx/od_tools/pkg/sim"
 )
 
 var cmdVars *FlagSetVars
@@ -12,10 +13,7 @@ var cmdVars *FlagSetVars
 func main() {
 	cmdVars = &FlagSetVars{}
 
-	commands := map[string]*flag.FlagSet{
-		GenerateLogCmd: cmdVars.GenerateLogCmd(),
-		ParseLogCmd:    cmdVars.ParseLogCmd(),
-	}
+	commands := cmdVars.Commands()
 
 	if len(os.Args) < 2 {
 		printUsage(commands)
@@ -38,16 +36,237 @@ func main() {
 			os.Exit(1)
 		}
 
-		gameLogCmd := NewGameLog(cmdVars.simPath, cmdVars.resultPath)
-		gameLogCmd.Execute()
+		fromHour, toHour, err := cmdVars.hourRange()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		options := sim.GameLogOptions{
+			From:          fromHour,
+			To:            toHour,
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+			Force:         cmdVars.force,
+			Recalc:        cmdVars.recalc,
+			Format:        cmdVars.format,
+		}
+
+		if sim.IsBatchPattern(cmdVars.simPath) || cmdVars.outDir != "" {
+			if cmdVars.watch {
+				fmt.Println("-watch can't be used with many sims")
+				os.Exit(1)
+			}
+			if cmdVars.resultPath != "" {
+				fmt.Println("-result can't be used with many sims, use -outdir")
+				os.Exit(1)
+			}
+
+			batchCmd, err := sim.NewBatchCmd(cmdVars.simPath, cmdVars.outDir, cmdVars.workers, options)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			if err := batchCmd.Execute(); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if cmdVars.watch {
+			watchCmd, err := sim.NewWatchCmd(cmdVars.simPath, cmdVars.resultPath, options)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			if err := watchCmd.Execute(); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		gameLogCmd, err := sim.NewGameLog(cmdVars.simPath, cmdVars.resultPath, options)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := gameLogCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	case ParseLogCmd:
 		if cmdVars.logPath == "" {
 			cmd.Usage()
 			os.Exit(1)
 		}
 
-		cmd := NewLogCmd(cmdVars.logPath, cmdVars.resultPath)
-		cmd.Execute()
+		logCmd, err := sim.NewLogCmd(cmdVars.logPath, cmdVars.resultPath, cmdVars.parseFormat)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := logCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case CheckVersionCmd:
+		if cmdVars.simPath == "" {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		versionCmd, err := sim.NewVersionCmd(cmdVars.simPath, cmdVars.layoutPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := versionCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case ValidateCmd:
+		if cmdVars.simPath == "" {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		validateCmd, err := sim.NewValidateCmd(cmdVars.simPath, cmdVars.resultPath, cmdVars.format, sim.GameLogOptions{
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+			Recalc:        cmdVars.recalc,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := validateCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case DiffCmd:
+		if cmd.NArg() != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		diffCmd, err := sim.NewDiffCmd(cmd.Arg(0), cmd.Arg(1), cmdVars.resultPath, cmdVars.format, sim.GameLogOptions{
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := diffCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case ClearInputsCmd:
+		if cmdVars.simPath == "" {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		clearCmd, err := sim.NewClearInputsCmd(cmdVars.simPath, cmdVars.outPath, cmdVars.clearBy, sim.GameLogOptions{
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := clearCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case Log2SimCmd:
+		if cmdVars.logPath == "" || cmdVars.simPath == "" {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		log2simCmd, err := sim.NewLog2SimCmd(cmdVars.logPath, cmdVars.simPath, cmdVars.outPath, cmdVars.clear, sim.GameLogOptions{
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := log2simCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case ApplyPlanCmd:
+		if cmdVars.planPath == "" || cmdVars.simPath == "" {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		planCmd, err := sim.NewApplyPlanCmd(cmdVars.planPath, cmdVars.simPath, cmdVars.outPath, cmdVars.clear, sim.GameLogOptions{
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := planCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case StatsCmd:
+		if cmdVars.simPath == "" {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		statsCmd, err := sim.NewStatsCmd(cmdVars.simPath, cmdVars.resultPath, cmdVars.hour, cmdVars.format, sim.GameLogOptions{
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+			Recalc:        cmdVars.recalc,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := statsCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case RoundTripCmd:
+		if cmdVars.simPath == "" {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		roundTripCmd, err := sim.NewRoundTripCmd(cmdVars.simPath, cmdVars.resultPath, cmdVars.format, sim.GameLogOptions{
+			LayoutPath:    cmdVars.layoutPath,
+			SkipDiscovery: !cmdVars.discover,
+			Recalc:        cmdVars.recalc,
+		})
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := roundTripCmd.Execute(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	default:
 		printUsage(commands)
 	}
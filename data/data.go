@@ -0,0 +1,8 @@
+// Package data embeds the game and sim workbook definitions so the tools
+// can be used without shipping the yml files next to the binary.
+package data
+
+import "embed"
+
+//go:embed *.yml races/*.yml layouts/*.yml
+var FS embed.FS
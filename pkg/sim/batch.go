@@ -0,0 +1,213 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+const (
+	BatchOK      = "ok"
+	BatchInvalid = "invalid"
+	BatchError   = "error"
+)
+
+var formatExtensions = map[string]string{
+	FormatText:   ".txt",
+	FormatJSON:   ".json",
+	FormatNDJSON: ".ndjson",
+}
+
+// BatchCmd generates logs of many sims at once, every sim by its own GameLogCmd
+type BatchCmd struct {
+	simPaths []string
+	outDir   string
+	workers  int
+	options  GameLogOptions
+}
+
+// BatchResult is the outcome of generating the log of one sim
+type BatchResult struct {
+	SimPath    string
+	ResultPath string
+	Status     string
+	Violations []Violation
+	Err        error
+}
+
+// IsBatchPattern tells if the sim path is a glob pattern of many sims
+func IsBatchPattern(simPath string) bool {
+	return strings.ContainsAny(simPath, "*?[")
+}
+
+func NewBatchCmd(pattern, outDir string, workers int, options GameLogOptions) (*BatchCmd, error) {
+	simPaths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, WrapError(err, "error on finding sims")
+	}
+	if len(simPaths) == 0 {
+		return nil, fmt.Errorf("no sims match %s", pattern)
+	}
+	sort.Strings(simPaths)
+
+	if outDir == "" {
+		return nil, fmt.Errorf("-outdir is required to generate logs of many sims")
+	}
+
+	if options.Format == "" {
+		options.Format = FormatText
+	}
+	if err := checkFormat(options.Format, FormatText, FormatJSON, FormatNDJSON); err != nil {
+		return nil, err
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	cmd := &BatchCmd{
+		simPaths: simPaths,
+		outDir:   outDir,
+		workers:  workers,
+		options:  options,
+	}
+
+	// Every sim needs its own result file
+	seen := map[string]string{}
+	for _, simPath := range simPaths {
+		resultPath := cmd.resultPath(simPath)
+		if other, ok := seen[resultPath]; ok {
+			return nil, fmt.Errorf("sims %s and %s have the same result file %s", other, simPath, resultPath)
+		}
+		seen[resultPath] = simPath
+	}
+
+	return cmd, nil
+}
+
+func (c *BatchCmd) resultPath(simPath string) string {
+	name := strings.TrimSuffix(filepath.Base(simPath), filepath.Ext(simPath))
+	return filepath.Join(c.outDir, name+formatExtensions[c.options.Format])
+}
+
+func (c *BatchCmd) Execute() error {
+	if err := os.MkdirAll(c.outDir, 0755); err != nil {
+		return WrapError(err, "error creating result directory")
+	}
+
+	results := c.Run()
+
+	for _, result := range results {
+		for _, violation := range result.Violations {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", result.SimPath, violation)
+		}
+	}
+
+	if err := WriteBatchSummary(os.Stdout, results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status != BatchOK {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sims failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// Run generates all logs with a pool of workers, results are in the order of sims
+func (c *BatchCmd) Run() []BatchResult {
+	results := make([]BatchResult, len(c.simPaths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers && i < len(c.simPaths); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results[job] = c.generate(c.simPaths[job])
+			}
+		}()
+	}
+
+	for job := range c.simPaths {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (c *BatchCmd) generate(simPath string) BatchResult {
+	result := BatchResult{SimPath: simPath, Status: BatchError}
+	resultPath := c.resultPath(simPath)
+
+	gameLog, err := NewGameLog(simPath, resultPath, c.options)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer gameLog.Close()
+
+	logResult, err := gameLog.Generate()
+	if logResult != nil {
+		result.Violations = logResult.Violations
+	}
+	if err != nil {
+		if hasErrors(result.Violations) {
+			result.Status = BatchInvalid
+		}
+		result.Err = err
+		return result
+	}
+	if logResult.HourErr != nil {
+		result.Err = logResult.HourErr
+		return result
+	}
+
+	if err := os.WriteFile(resultPath, []byte(logResult.Log), 0644); err != nil {
+		result.Err = WrapError(err, "error writing to file")
+		return result
+	}
+
+	result.Status = BatchOK
+	result.ResultPath = resultPath
+	return result
+}
+
+// WriteBatchSummary writes a table of generated logs and failed sims
+func WriteBatchSummary(w io.Writer, results []BatchResult) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "SIM\tSTATUS\tPROBLEMS\tRESULT")
+
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Status]++
+
+		outcome := result.ResultPath
+		if result.Err != nil {
+			outcome = result.Err.Error()
+		}
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\n", result.SimPath, result.Status, len(result.Violations), outcome)
+	}
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%d sims: %d ok, %d invalid, %d failed\n",
+		len(results), counts[BatchOK], counts[BatchInvalid], counts[BatchError])
+	return err
+}
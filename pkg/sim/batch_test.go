@@ -0,0 +1,184 @@
+package sim
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestNewBatchCmdErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"one/sim.xlsm", "two/sim.xlsm"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("not a sim"), 0644)
+	}
+
+	testCases := []struct {
+		name        string
+		pattern     string
+		outDir      string
+		expectedErr string
+	}{
+		{"No sims", filepath.Join(dir, "*.ods"), "logs", "no sims match"},
+		{"No outdir", filepath.Join(dir, "*", "*.xlsm"), "", "-outdir is required"},
+		{"Same result file", filepath.Join(dir, "*", "*.xlsm"), "logs", "have the same result file"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewBatchCmd(tc.pattern, tc.outDir, 2, GameLogOptions{})
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("Expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBatchCmdRun(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("sim%d.xlsm", i)), []byte("not a sim"), 0644)
+	}
+
+	cmd, err := NewBatchCmd(filepath.Join(dir, "*.xlsm"), filepath.Join(dir, "logs"), 2, GameLogOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results := cmd.Run()
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results))
+	}
+
+	for i, result := range results {
+		if result.SimPath != filepath.Join(dir, fmt.Sprintf("sim%d.xlsm", i)) {
+			t.Errorf("Results are not in the order of sims: %s", result.SimPath)
+		}
+		if result.Status != BatchError || result.Err == nil {
+			t.Errorf("Expected error result for %s, got %+v", result.SimPath, result)
+		}
+	}
+
+	if got := cmd.resultPath(results[0].SimPath); got != filepath.Join(dir, "logs", "sim0.json") {
+		t.Errorf("Incorrect result path: %s", got)
+	}
+}
+
+// writeBatchSim saves a sim with the hour times and the cells of one player
+func writeBatchSim(t *testing.T, path string, cells map[string]map[string]string) {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	for _, sheet := range append(mustDefaultLayout().sheets(), Constants) {
+		workbook.NewSheet(sheet)
+	}
+
+	workbook.SetCellValue(Overview, "B15", "5/18/2024")
+	for hr := 1; hr <= LastHour; hr++ {
+		workbook.SetCellValue(Imps, fmt.Sprintf("BY%d", hr+3), "18:00")
+		workbook.SetCellValue(Imps, fmt.Sprintf("BZ%d", hr+3), "00:00")
+	}
+
+	for sheet, values := range cells {
+		for cell, value := range values {
+			if err := workbook.SetCellValue(sheet, cell, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := workbook.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchCmdRunSims(t *testing.T) {
+	dir := t.TempDir()
+	writeBatchSim(t, filepath.Join(dir, "alice.xlsx"), map[string]map[string]string{Military: {"Y4": "35%"}})
+	writeBatchSim(t, filepath.Join(dir, "bob.xlsx"), map[string]map[string]string{Military: {"Y4": "90%"}})
+	writeBatchSim(t, filepath.Join(dir, "carol.xlsx"), map[string]map[string]string{Military: {"Z4": "95%", "Z5": "-1%"}})
+	os.WriteFile(filepath.Join(dir, "dave.xlsx"), []byte("not a sim"), 0644)
+
+	outDir := filepath.Join(dir, "logs")
+	os.MkdirAll(outDir, 0755)
+
+	// Discovery needs the header labels of a real sim
+	cmd, err := NewBatchCmd(filepath.Join(dir, "*.xlsx"), outDir, 4, GameLogOptions{SkipDiscovery: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results := cmd.Run()
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+
+	for i, rate := range []string{"35%", "90%"} {
+		result := results[i]
+		if result.Status != BatchOK || result.Err != nil || len(result.Violations) != 0 {
+			t.Fatalf("Expected ok result for %s, got %+v", result.SimPath, result)
+		}
+		if result.ResultPath != cmd.resultPath(result.SimPath) {
+			t.Errorf("Incorrect result path of %s: %s", result.SimPath, result.ResultPath)
+		}
+
+		content, err := os.ReadFile(result.ResultPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "Draftrate changed to "+rate) {
+			t.Errorf("Log of %s has another sim's actions:\n%s", result.SimPath, content)
+		}
+	}
+
+	invalid := results[2]
+	if invalid.Status != BatchInvalid || len(invalid.Violations) != 2 || invalid.ResultPath != "" {
+		t.Errorf("Expected 2 violations of carol.xlsx, got %+v", invalid)
+	}
+	for _, violation := range invalid.Violations {
+		if violation.Rule != "draftrate" {
+			t.Errorf("Unexpected violation of carol.xlsx: %s", violation)
+		}
+	}
+
+	failed := results[3]
+	if failed.Status != BatchError || failed.Err == nil || len(failed.Violations) != 0 {
+		t.Errorf("Expected error result of dave.xlsx, got %+v", failed)
+	}
+
+	for _, name := range []string{"carol.txt", "dave.txt"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); !os.IsNotExist(err) {
+			t.Errorf("Failed sim should not have a result file %s", name)
+		}
+	}
+}
+
+func TestWriteBatchSummary(t *testing.T) {
+	results := []BatchResult{
+		{SimPath: "sims/a.xlsm", ResultPath: "logs/a.txt", Status: BatchOK},
+		{SimPath: "sims/bob.xlsm", Status: BatchInvalid, Violations: make([]Violation, 3), Err: fmt.Errorf("sim has 3 validation problems")},
+		{SimPath: "sims/c.xlsm", Status: BatchError, Err: fmt.Errorf("error on opening sim file")},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBatchSummary(&buf, results); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "" +
+		"SIM            STATUS   PROBLEMS  RESULT\n" +
+		"sims/a.xlsm    ok       0         logs/a.txt\n" +
+		"sims/bob.xlsm  invalid  3         sim has 3 validation problems\n" +
+		"sims/c.xlsm    error    0         error on opening sim file\n" +
+		"3 sims: 1 ok, 1 invalid, 1 failed\n"
+
+	if buf.String() != expected {
+		t.Errorf("Incorrect summary:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
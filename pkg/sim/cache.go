@@ -0,0 +1,104 @@
+package sim
+
+import (
+	"github.com/xuri/excelize/v2"
+)
+
+// RowsReader is a sim able to read whole sheets, like an excelize workbook
+type RowsReader interface {
+	Sim
+	GetRows(sheet string, opts ...excelize.Options) ([][]string, error)
+}
+
+// MergeCellsReader is a sim with merged cells, like an excelize workbook
+type MergeCellsReader interface {
+	GetMergeCells(sheet string) ([]excelize.MergeCell, error)
+}
+
+// CachedSim reads every sheet once on its first use and then returns cells
+// from memory instead of looking them up in the workbook on every read.
+// Values are formatted the same way as by the default excelize options, and every
+// cell of a merged range has the value of its top left cell like in excelize.
+type CachedSim struct {
+	RowsReader
+	grid *gridSim
+}
+
+func NewCachedSim(sim RowsReader) *CachedSim {
+	return &CachedSim{
+		RowsReader: sim,
+		grid:       newGridSim(),
+	}
+}
+
+func (s *CachedSim) GetCellValue(sheet, cell string, _ ...excelize.Options) (string, error) {
+	if err := s.load(sheet); err != nil {
+		return "", err
+	}
+
+	return s.grid.GetCellValue(sheet, cell)
+}
+
+func (s *CachedSim) GetRows(sheet string, _ ...excelize.Options) ([][]string, error) {
+	if err := s.load(sheet); err != nil {
+		return nil, err
+	}
+
+	return s.grid.GetRows(sheet)
+}
+
+// GetSheetList keeps the version check working through the decorator
+func (s *CachedSim) GetSheetList() []string {
+	if lister, ok := s.RowsReader.(SheetLister); ok {
+		return lister.GetSheetList()
+	}
+	return nil
+}
+
+func (s *CachedSim) load(sheet string) error {
+	if _, ok := s.grid.rows[sheet]; ok {
+		return nil
+	}
+
+	rows, err := s.RowsReader.GetRows(sheet)
+	if err != nil {
+		return err
+	}
+
+	s.grid.addSheet(sheet)
+	s.grid.rows[sheet] = rows
+
+	return s.loadMergeCells(sheet)
+}
+
+// loadMergeCells fills the merged ranges of the sheet, GetRows only has their top left cells
+func (s *CachedSim) loadMergeCells(sheet string) error {
+	merger, ok := s.RowsReader.(MergeCellsReader)
+	if !ok {
+		return nil
+	}
+
+	mergeCells, err := merger.GetMergeCells(sheet)
+	if err != nil {
+		return err
+	}
+
+	for _, mergeCell := range mergeCells {
+		startCol, startRow, err := excelize.CellNameToCoordinates(mergeCell.GetStartAxis())
+		if err != nil {
+			return err
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(mergeCell.GetEndAxis())
+		if err != nil {
+			return err
+		}
+
+		for row := startRow; row <= endRow; row++ {
+			for col := startCol; col <= endCol; col++ {
+				s.grid.setCell(sheet, row, col, mergeCell.GetCellValue())
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCachedSim(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Sheet1"
+	percentStyle, _ := file.NewStyle(&excelize.Style{NumFmt: 9})
+	thousandsStyle, _ := file.NewStyle(&excelize.Style{NumFmt: 3})
+
+	file.SetCellValue(sheet, "A1", "Sylvan ")
+	file.SetCellValue(sheet, "B2", 0.35)
+	file.SetCellStyle(sheet, "B2", "B2", percentStyle)
+	file.SetCellValue(sheet, "C3", 12345)
+	file.SetCellStyle(sheet, "C3", "C3", thousandsStyle)
+	file.SetCellFormula(sheet, "D3", "C3*2")
+	file.SetCellValue(sheet, "E5", 1.5)
+
+	cached := NewCachedSim(file)
+
+	for _, cell := range []string{"A1", "A2", "B2", "C3", "D3", "E5", "F5", "A10", "Z100"} {
+		expected, err := file.GetCellValue(sheet, cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		value, err := cached.GetCellValue(sheet, cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value != expected {
+			t.Errorf("Incorrect %s value: got %q, want %q", cell, value, expected)
+		}
+	}
+
+	if _, err := cached.GetCellValue("Missing", "A1"); err == nil {
+		t.Errorf("Expected error for a missing sheet")
+	}
+
+	// Changes after the first read are not seen, the sheet is read once
+	file.SetCellValue(sheet, "A1", "Changed")
+	if value, _ := cached.GetCellValue(sheet, "A1"); value != "Sylvan " {
+		t.Errorf("Expected cached value, got %q", value)
+	}
+}
+
+func TestCachedSimMergedCells(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Sheet1"
+	file.SetCellValue(sheet, "B2", "Spies")
+	file.SetCellValue(sheet, "D2", "Wizards")
+	if err := file.MergeCell(sheet, "B2", "C3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	file.SetCellValue(sheet, "B4", 10)
+
+	cached := NewCachedSim(file)
+
+	for _, cell := range []string{"B2", "C2", "B3", "C3", "D2", "D3", "B4", "C4"} {
+		expected, err := file.GetCellValue(sheet, cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		value, err := cached.GetCellValue(sheet, cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value != expected {
+			t.Errorf("Incorrect %s value: got %q, want %q", cell, value, expected)
+		}
+	}
+
+	if value, _ := cached.GetCellValue(sheet, "C3"); value != "Spies" {
+		t.Errorf("Merged header should be read from every cell, got %q", value)
+	}
+}
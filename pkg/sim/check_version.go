@@ -0,0 +1,114 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+)
+
+type VersionCmd struct {
+	simPath    string
+	layoutPath string
+	sim        Sim
+	layouts    []*Layout
+	revisions  []Revision
+}
+
+// NewVersionCmd prepares a check of the sim against the known revisions and layouts,
+// and the layout from layoutPath when it is set.
+func NewVersionCmd(simPath, layoutPath string) (*VersionCmd, error) {
+	layouts, err := KnownLayouts()
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := KnownRevisions()
+	if err != nil {
+		return nil, err
+	}
+
+	if layoutPath != "" {
+		layout, err := LoadLayout(layoutPath)
+		if err != nil {
+			return nil, err
+		}
+		if layout.Revision == "" {
+			layout.Revision = layoutPath
+		}
+		layouts = append([]*Layout{layout}, layouts...)
+	}
+
+	sim, err := OpenSim(simPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersionCmd{
+		simPath:    simPath,
+		layoutPath: layoutPath,
+		sim:        sim,
+		layouts:    layouts,
+		revisions:  revisions,
+	}, nil
+}
+
+// Execute prints the fingerprint report, it returns an error when no layout supports the sim.
+func (c *VersionCmd) Execute() error {
+	defer c.sim.Close()
+
+	check, err := CheckVersion(c.sim, c.layouts)
+	if err != nil {
+		return err
+	}
+	check.MatchRevisions(c.revisions)
+
+	fmt.Print(check.Report())
+
+	if match, ok := check.Best(); !ok {
+		return fmt.Errorf("%s is not supported, closest layout is %q", c.simPath, match.Revision)
+	}
+
+	return nil
+}
+
+func (v *VersionCheck) Report() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Fingerprint: %s\n", v.Fingerprint))
+	sb.WriteString(fmt.Sprintf("Constants:   %s\n", v.ConstantsHash))
+	sb.WriteString(fmt.Sprintf("Sheets:      %s\n", strings.Join(v.Sheets, ", ")))
+
+	switch {
+	case v.Revision == nil:
+		sb.WriteString("Revision:    unknown\n")
+	case v.Revision.Exact():
+		sb.WriteString(fmt.Sprintf("Revision:    %s (layout %s)\n", v.Revision.Revision.Name, v.Revision.Revision.Layout))
+	default:
+		sb.WriteString(fmt.Sprintf("Revision:    unknown, closest is %s (%d of %d parts, layout %s)\n",
+			v.Revision.Revision.Name, v.Revision.Matched, v.Revision.Total, v.Revision.Revision.Layout))
+	}
+	sb.WriteString("\n")
+
+	for _, match := range v.Matches {
+		status := "not supported"
+		if match.Supported() {
+			status = "supported"
+		}
+
+		sb.WriteString(fmt.Sprintf("%s: %s (%d of %d headers, fingerprint %s)\n",
+			match.Revision, status, match.Matched, match.Total, match.Fingerprint))
+
+		if len(match.MissingSheets) > 0 {
+			sb.WriteString(fmt.Sprintf("  missing sheets: %s\n", strings.Join(match.MissingSheets, ", ")))
+		}
+		for _, mismatch := range match.Mismatches {
+			sb.WriteString(fmt.Sprintf("  %s\n", mismatch))
+		}
+	}
+
+	if match, ok := v.Best(); ok && (v.Revision == nil || !v.Revision.Exact()) {
+		sb.WriteString("\nThe sim is a new revision, add it to data/revisions.yml:\n")
+		sb.WriteString(v.revisionEntry(match))
+	}
+
+	return sb.String()
+}
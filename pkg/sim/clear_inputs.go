@@ -0,0 +1,250 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Ways to find the input cells of the sim
+const (
+	ClearByStyle  = "style"
+	ClearByLayout = "layout"
+)
+
+// ClearInputsCmd blanks the cells filled in by the player so the sim can be used for a new build
+type ClearInputsCmd struct {
+	simPath string
+	outPath string
+	by      string
+	layout  *Layout
+	options GameLogOptions
+}
+
+// ClearedSheet is the number of input cells cleared in a sheet
+type ClearedSheet struct {
+	Sheet string
+	Cells int
+}
+
+func NewClearInputsCmd(simPath, outPath, by string, options GameLogOptions) (*ClearInputsCmd, error) {
+	if err := checkOutPath(simPath, outPath); err != nil {
+		return nil, err
+	}
+
+	if by == "" {
+		by = ClearByStyle
+	}
+	if by != ClearByStyle && by != ClearByLayout {
+		return nil, fmt.Errorf("unknown input detection %q, use %s or %s", by, ClearByStyle, ClearByLayout)
+	}
+
+	layout, err := LoadLayout(options.LayoutPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClearInputsCmd{
+		simPath: simPath,
+		outPath: outPath,
+		by:      by,
+		layout:  layout,
+		options: options,
+	}, nil
+}
+
+func (c *ClearInputsCmd) Execute() error {
+	// Only excelize keeps the rest of the workbook including macros when it's saved
+	workbook, err := excelize.OpenFile(c.simPath)
+	if err != nil {
+		return WrapError(err, "error on opening sim file")
+	}
+	defer workbook.Close()
+
+	if c.by == ClearByLayout && !c.options.SkipDiscovery {
+		if c.layout, err = discoverLayout(c.layout, workbook, c.simPath); err != nil {
+			return err
+		}
+	}
+
+	cleared, err := ClearInputs(workbook, c.layout, c.by)
+	if err != nil {
+		return err
+	}
+
+	if err := workbook.SaveAs(c.outPath); err != nil {
+		return WrapError(err, "error saving cleared sim")
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	total := 0
+	for _, sheet := range cleared {
+		fmt.Fprintf(table, "%s\t%d\n", sheet.Sheet, sheet.Cells)
+		total += sheet.Cells
+	}
+	fmt.Fprintf(table, "Total\t%d\n", total)
+	table.Flush()
+
+	fmt.Printf("Successfully wrote cleared sim to %s, open it in Excel to recalculate formulas\n", c.outPath)
+
+	return nil
+}
+
+// ClearInputs blanks input cells of protection hours. Cells with formulas and the
+// Constants sheet are never changed, styles of the cleared cells are kept.
+func ClearInputs(workbook *excelize.File, layout *Layout, by string) ([]ClearedSheet, error) {
+	counts := map[string]int{}
+	firstRow, lastRow := layout.FirstHourRow, layout.FirstHourRow+LastHour-1
+
+	clear := func(sheet, cell string) error {
+		formula, err := workbook.GetCellFormula(sheet, cell)
+		if err != nil {
+			return WrapError(err, "error reading formula")
+		}
+		if formula != "" {
+			return nil
+		}
+
+		if err := workbook.SetCellValue(sheet, cell, nil); err != nil {
+			return WrapError(err, fmt.Sprintf("error clearing %s!%s", sheet, cell))
+		}
+		counts[sheet]++
+		return nil
+	}
+
+	sheets := []string{}
+	for _, sheet := range layout.sheets() {
+		if sheet != Constants && containsString(workbook.GetSheetList(), sheet) {
+			sheets = append(sheets, sheet)
+		}
+	}
+
+	switch by {
+	case ClearByStyle:
+		inputStyles := map[int]bool{}
+
+		for _, sheet := range sheets {
+			rows, err := workbook.GetRows(sheet)
+			if err != nil {
+				return nil, WrapError(err, "error reading sheet "+sheet)
+			}
+
+			for row := firstRow; row <= lastRow && row <= len(rows); row++ {
+				for col, value := range rows[row-1] {
+					if value == "" {
+						continue
+					}
+
+					cell, _ := excelize.CoordinatesToCellName(col+1, row)
+					styleID, err := workbook.GetCellStyle(sheet, cell)
+					if err != nil {
+						return nil, WrapError(err, "error reading cell style")
+					}
+
+					input, ok := inputStyles[styleID]
+					if !ok {
+						style, err := workbook.GetStyle(styleID)
+						if err != nil {
+							return nil, WrapError(err, "error reading cell style")
+						}
+						input = isInputFill(style.Fill)
+						inputStyles[styleID] = input
+					}
+
+					if input {
+						if err := clear(sheet, cell); err != nil {
+							return nil, err
+						}
+					}
+				}
+			}
+		}
+
+	case ClearByLayout:
+		for _, entry := range layout.inputEntries() {
+			if !containsString(sheets, entry.Sheet) {
+				continue
+			}
+
+			for row := firstRow; row <= lastRow; row++ {
+				cell := entry.Column + strconv.Itoa(row)
+				value, err := workbook.GetCellValue(entry.Sheet, cell)
+				if err != nil {
+					return nil, WrapError(err, "error reading input")
+				}
+				if value == "" {
+					continue
+				}
+
+				if err := clear(entry.Sheet, cell); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	cleared := []ClearedSheet{}
+	for _, sheet := range sheets {
+		cleared = append(cleared, ClearedSheet{Sheet: sheet, Cells: counts[sheet]})
+	}
+
+	return cleared, nil
+}
+
+// isInputFill tells if a cell has the orange fill the sim uses for inputs
+func isInputFill(fill excelize.Fill) bool {
+	if fill.Type != "pattern" || fill.Pattern == 0 || len(fill.Color) == 0 {
+		return false
+	}
+
+	return isOrange(fill.Color[0])
+}
+
+// isOrange checks the hue of a hex color, light and dark shades of orange are included
+func isOrange(hexColor string) bool {
+	rgb, err := strconv.ParseUint(hexColor, 16, 32)
+	if err != nil || len(hexColor) != 6 {
+		return false
+	}
+
+	r := float64(rgb>>16&0xFF) / 255
+	g := float64(rgb>>8&0xFF) / 255
+	b := float64(rgb&0xFF) / 255
+
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	lightness := (maxC + minC) / 2
+	if maxC == minC || maxC != r || lightness < 0.3 || lightness > 0.92 {
+		return false
+	}
+
+	saturation := (maxC - minC) / (1 - math.Abs(2*lightness-1))
+	hue := 60 * (g - b) / (maxC - minC)
+
+	return saturation >= 0.45 && hue >= 15 && hue <= 50
+}
+
+// checkOutPath makes sure a changed sim is saved as a new file
+func checkOutPath(simPath, outPath string) error {
+	if outPath == "" {
+		return fmt.Errorf("-out is required")
+	}
+	if absPath(outPath) == absPath(simPath) {
+		return fmt.Errorf("-out must be a new file, the sim is not overwritten")
+	}
+
+	return nil
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
@@ -0,0 +1,178 @@
+package sim
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func newClearInputsWorkbook(t *testing.T) *excelize.File {
+	workbook := excelize.NewFile()
+	for _, sheet := range append(mustDefaultLayout().sheets(), Constants) {
+		workbook.NewSheet(sheet)
+	}
+
+	orange, _ := workbook.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"F8CBAD"}}})
+	yellow, _ := workbook.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFFF00"}}})
+
+	cells := []struct {
+		sheet   string
+		cell    string
+		value   interface{}
+		formula string
+		style   int
+	}{
+		{Military, "AX6", 3, "", orange},       // input of hour 3
+		{Military, "AX2", "Satyr", "", orange}, // header
+		{Military, "E6", 100, "E5-AX6", orange},
+		{Military, "F6", 7, "", yellow},
+		{Military, "Y4", "35%", "", 0},   // draft rate input without style
+		{Explore, "S76", 20, "", orange}, // input of hour 73
+		{Explore, "S77", 20, "", orange},
+		{Constants, "B4", 500, "", orange},
+	}
+
+	for _, c := range cells {
+		if err := workbook.SetCellValue(c.sheet, c.cell, c.value); err != nil {
+			t.Fatal(err)
+		}
+		if c.formula != "" {
+			workbook.SetCellFormula(c.sheet, c.cell, c.formula)
+		}
+		if c.style != 0 {
+			workbook.SetCellStyle(c.sheet, c.cell, c.cell, c.style)
+		}
+	}
+
+	return workbook
+}
+
+func TestClearInputs(t *testing.T) {
+	testCases := []struct {
+		by      string
+		cleared []string
+		kept    []string
+	}{
+		{
+			by:      ClearByStyle,
+			cleared: []string{"Military!AX6", "Explore!S76"},
+			kept:    []string{"Military!AX2", "Military!E6", "Military!F6", "Military!Y4", "Explore!S77", "Constants!B4"},
+		},
+		{
+			by:      ClearByLayout,
+			cleared: []string{"Military!AX6", "Military!Y4", "Explore!S76"},
+			kept:    []string{"Military!AX2", "Military!E6", "Military!F6", "Explore!S77", "Constants!B4"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.by, func(t *testing.T) {
+			workbook := newClearInputsWorkbook(t)
+			defer workbook.Close()
+
+			cleared, err := ClearInputs(workbook, mustDefaultLayout(), tc.by)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			total := 0
+			for _, sheet := range cleared {
+				total += sheet.Cells
+			}
+			if total != len(tc.cleared) {
+				t.Errorf("Expected %d cleared cells, got %v", len(tc.cleared), cleared)
+			}
+
+			for _, ref := range tc.cleared {
+				sheet, cell := splitRef(ref)
+				if value, _ := workbook.GetCellValue(sheet, cell); value != "" {
+					t.Errorf("Expected %s to be cleared, got %q", ref, value)
+				}
+			}
+			for _, ref := range tc.kept {
+				sheet, cell := splitRef(ref)
+				if value, _ := workbook.GetCellValue(sheet, cell); value == "" {
+					t.Errorf("Expected %s to be kept", ref)
+				}
+			}
+
+			if formula, _ := workbook.GetCellFormula(Military, "E6"); formula != "E5-AX6" {
+				t.Errorf("Formula was changed: %q", formula)
+			}
+		})
+	}
+}
+
+func TestClearInputsCmdKeepsMacros(t *testing.T) {
+	dir := t.TempDir()
+	simPath := filepath.Join(dir, "sim.xlsm")
+	outPath := filepath.Join(dir, "template.xlsm")
+
+	// Minimal VBA project, excelize only checks the OLE signature
+	vbaProject := append([]byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, bytes.Repeat([]byte("macros"), 100)...)
+
+	workbook := newClearInputsWorkbook(t)
+	if err := workbook.AddVBAProject(vbaProject); err != nil {
+		t.Fatal(err)
+	}
+	if err := workbook.SaveAs(simPath); err != nil {
+		t.Fatal(err)
+	}
+	workbook.Close()
+
+	cmd, err := NewClearInputsCmd(simPath, outPath, ClearByStyle, GameLogOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	archive, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	found := false
+	for _, file := range archive.File {
+		if file.Name != "xl/vbaProject.bin" {
+			continue
+		}
+		content, _ := file.Open()
+		data, _ := io.ReadAll(content)
+		content.Close()
+		found = bytes.Equal(data, vbaProject)
+	}
+	if !found {
+		t.Errorf("Macros are missing in the cleared sim")
+	}
+
+	if _, err := NewClearInputsCmd(simPath, simPath, ClearByStyle, GameLogOptions{}); err == nil {
+		t.Errorf("Expected error when the sim would be overwritten")
+	}
+}
+
+func TestIsOrange(t *testing.T) {
+	for color, expected := range map[string]bool{
+		"FFC000": true, "ED7D31": true, "F8CBAD": true, "F4B084": true, "C65911": true,
+		"FFFF00": false, "FF0000": false, "FFFFFF": false, "000000": false, "5B9BD5": false, "bad": false,
+	} {
+		if isOrange(color) != expected {
+			t.Errorf("isOrange(%s) should be %v", color, expected)
+		}
+	}
+}
+
+func splitRef(ref string) (string, string) {
+	for i := range ref {
+		if ref[i] == '!' {
+			return ref[:i], ref[i+1:]
+		}
+	}
+	return "", ref
+}
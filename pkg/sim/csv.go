@@ -0,0 +1,132 @@
+package sim
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OpenCSV reads a sim exported sheet by sheet to CSV files, from a directory
+// or a zip archive. Every file is a sheet named after the file, Google Sheets
+// names like "OpenDominionSim - Overview.csv" are supported too.
+func OpenCSV(simPath string) (Sim, error) {
+	info, err := os.Stat(simPath)
+	if err != nil {
+		return nil, WrapError(err, "error on opening csv sim")
+	}
+
+	if info.IsDir() {
+		return openCSVDir(simPath)
+	}
+
+	return openCSVZip(simPath)
+}
+
+func openCSVDir(dir string) (Sim, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		return nil, WrapError(err, "error on opening csv sim")
+	}
+	sort.Strings(names)
+
+	grid := newGridSim()
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, WrapError(err, "error on opening csv sim")
+		}
+
+		err = readCSVSheet(grid, csvSheetName(name), file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return checkCSVSheets(dir, grid)
+}
+
+func openCSVZip(zipPath string) (Sim, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, WrapError(err, "error on opening csv sim")
+	}
+	defer archive.Close()
+
+	grid := newGridSim()
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(file.Name), ".csv") {
+			continue
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			return nil, WrapError(err, "error on opening csv sim")
+		}
+
+		err = readCSVSheet(grid, csvSheetName(file.Name), content)
+		content.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return checkCSVSheets(zipPath, grid)
+}
+
+func checkCSVSheets(simPath string, grid *gridSim) (Sim, error) {
+	if len(grid.sheets) == 0 {
+		return nil, fmt.Errorf("error on opening csv sim: %s has no csv files", simPath)
+	}
+
+	return grid, nil
+}
+
+// csvSheetName returns the sheet name of an exported file
+func csvSheetName(fileName string) string {
+	name := strings.TrimSuffix(path.Base(filepath.ToSlash(fileName)), path.Ext(fileName))
+
+	if i := strings.LastIndex(name, " - "); i >= 0 {
+		name = name[i+len(" - "):]
+	}
+
+	return name
+}
+
+func readCSVSheet(grid *gridSim, sheet string, r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	grid.addSheet(sheet)
+
+	row, nextLine := 0, 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return WrapError(err, fmt.Sprintf("error reading csv sheet %s", sheet))
+		}
+
+		// Reader skips empty lines, they're still empty rows of the sheet
+		line, _ := reader.FieldPos(0)
+		row += line - nextLine + 1
+		lastLine, _ := reader.FieldPos(len(record) - 1)
+		nextLine = lastLine + strings.Count(record[len(record)-1], "\n") + 1
+
+		for i, value := range record {
+			if row == 1 && i == 0 {
+				value = strings.TrimPrefix(value, "\ufeff")
+			}
+			grid.setCell(sheet, row, i+1, value)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package sim
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var csvTestFiles = map[string]string{
+	"OpenDominionSim - Overview.csv": "\ufeffRace,Sylvan\n,,\"1,234\"\n",
+	"Imps.csv":                       "Line\n\n\"Multi\nline\",,35%\n\nEnd\n",
+}
+
+func TestOpenCSV(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range csvTestFiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "export.zip")
+	writeTestZip(t, zipPath, csvTestFiles)
+
+	for _, simPath := range []string{dir, zipPath} {
+		t.Run(filepath.Base(simPath), func(t *testing.T) {
+			sim, err := OpenSim(simPath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer sim.Close()
+
+			testCases := []struct {
+				sheet    string
+				cell     string
+				expected string
+			}{
+				{Overview, "A1", "Race"},
+				{Overview, "B1", "Sylvan"},
+				{Overview, "C2", "1,234"},
+				{Overview, "D2", ""},
+				{Imps, "A1", "Line"},
+				{Imps, "A2", ""},
+				{Imps, "A3", "Multi\nline"},
+				{Imps, "C3", "35%"},
+				{Imps, "A4", ""},
+				{Imps, "A5", "End"},
+			}
+
+			for _, tc := range testCases {
+				value, err := sim.GetCellValue(tc.sheet, tc.cell)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if value != tc.expected {
+					t.Errorf("Incorrect %s!%s value: got %q, want %q", tc.sheet, tc.cell, value, tc.expected)
+				}
+			}
+
+			sheets := sim.(SheetLister).GetSheetList()
+			if len(sheets) != 2 || !containsString(sheets, Overview) || !containsString(sheets, Imps) {
+				t.Errorf("Incorrect sheets: %v", sheets)
+			}
+		})
+	}
+}
+
+func TestOpenCSVWithoutFiles(t *testing.T) {
+	_, err := OpenSim(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "no csv files") {
+		t.Errorf("Expected missing csv files error, got %v", err)
+	}
+}
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	for name, data := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
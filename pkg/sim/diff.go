@@ -0,0 +1,304 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// CellChange is a sim value that differs between two sims. Hour is 0 for Constants.
+type CellChange struct {
+	Sheet string `json:"sheet"`
+	Hour  int    `json:"hour,omitempty"`
+	Cell  string `json:"cell"`
+	Label string `json:"label"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// SimDiff lists changed Constants and player inputs of two sims
+type SimDiff struct {
+	Old     string       `json:"old"`
+	New     string       `json:"new"`
+	Changes []CellChange `json:"changes"`
+}
+
+type DiffCmd struct {
+	oldPath    string
+	newPath    string
+	resultPath string
+	format     string
+	options    GameLogOptions
+}
+
+func NewDiffCmd(oldPath, newPath, resultPath, format string, options GameLogOptions) (*DiffCmd, error) {
+	if format == "" {
+		format = FormatText
+	}
+	if err := checkFormat(format, FormatText, FormatJSON); err != nil {
+		return nil, err
+	}
+
+	return &DiffCmd{
+		oldPath:    oldPath,
+		newPath:    newPath,
+		resultPath: resultPath,
+		format:     format,
+		options:    options,
+	}, nil
+}
+
+func (c *DiffCmd) Execute() error {
+	oldSim, oldLayout, err := c.open(c.oldPath)
+	if err != nil {
+		return err
+	}
+	defer oldSim.Close()
+
+	newSim, newLayout, err := c.open(c.newPath)
+	if err != nil {
+		return err
+	}
+	defer newSim.Close()
+
+	diff, err := DiffSims(oldSim, oldLayout, newSim, newLayout)
+	if err != nil {
+		return err
+	}
+	diff.Old, diff.New = c.oldPath, c.newPath
+
+	var sb strings.Builder
+	if err := WriteDiff(&sb, diff, c.format); err != nil {
+		return err
+	}
+
+	return writeResult(c.resultPath, []byte(sb.String()))
+}
+
+// open reads a sim with its own layout, columns may move between sim versions
+func (c *DiffCmd) open(simPath string) (Sim, *Layout, error) {
+	layout, err := LoadLayout(c.options.LayoutPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sim, err := OpenSim(simPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !c.options.SkipDiscovery {
+		if layout, err = discoverLayout(layout, sim, simPath); err != nil {
+			sim.Close()
+			return nil, nil, err
+		}
+	}
+
+	return sim, layout, nil
+}
+
+// DiffSims compares the Constants sheets and the per-hour inputs of two sims.
+// Changes are ordered by sheet and hour, inputs are matched by their layout entry.
+func DiffSims(oldSim Sim, oldLayout *Layout, newSim Sim, newLayout *Layout) (*SimDiff, error) {
+	diff := &SimDiff{Changes: []CellChange{}}
+
+	changes, err := diffConstants(oldSim, newSim)
+	if err != nil {
+		return nil, err
+	}
+	diff.Changes = append(diff.Changes, changes...)
+
+	oldEntries := oldLayout.inputEntries()
+	newEntries := newLayout.inputEntries()
+
+	for _, sheet := range newLayout.sheets() {
+		for hr := 1; hr <= LastHour; hr++ {
+			for i, entry := range newEntries {
+				if entry.Sheet != sheet {
+					continue
+				}
+
+				oldCell := fmt.Sprintf("%s%d", oldEntries[i].Column, oldLayout.FirstHourRow+hr-1)
+				newCell := fmt.Sprintf("%s%d", entry.Column, newLayout.FirstHourRow+hr-1)
+
+				oldValue, err := oldSim.GetCellValue(oldEntries[i].Sheet, oldCell)
+				if err != nil {
+					return nil, WrapError(err, "error reading old sim")
+				}
+				newValue, err := newSim.GetCellValue(sheet, newCell)
+				if err != nil {
+					return nil, WrapError(err, "error reading new sim")
+				}
+
+				oldValue, newValue = strings.TrimSpace(oldValue), strings.TrimSpace(newValue)
+				if oldValue == newValue {
+					continue
+				}
+
+				label, err := headerLabel(newSim, newLayout, sheet, entry)
+				if err != nil {
+					return nil, err
+				}
+
+				diff.Changes = append(diff.Changes, CellChange{
+					Sheet: sheet,
+					Hour:  hr,
+					Cell:  newCell,
+					Label: label,
+					Old:   oldValue,
+					New:   newValue,
+				})
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// diffConstants compares every cell of the Constants sheets
+func diffConstants(oldSim, newSim Sim) ([]CellChange, error) {
+	oldRows, err := readSheetRows(oldSim, Constants)
+	if err != nil {
+		return nil, err
+	}
+	newRows, err := readSheetRows(newSim, Constants)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []CellChange{}
+	for row := 0; row < max(len(oldRows), len(newRows)); row++ {
+		oldRow, newRow := rowAt(oldRows, row), rowAt(newRows, row)
+
+		for col := 0; col < max(len(oldRow), len(newRow)); col++ {
+			oldValue := strings.TrimSpace(valueAt(oldRow, col))
+			newValue := strings.TrimSpace(valueAt(newRow, col))
+			if oldValue == newValue {
+				continue
+			}
+
+			cell, _ := excelize.CoordinatesToCellName(col+1, row+1)
+			changes = append(changes, CellChange{
+				Sheet: Constants,
+				Cell:  cell,
+				Label: rowLabel(newRow, oldRow, col),
+				Old:   oldValue,
+				New:   newValue,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// readSheetRows reads a whole sheet, a missing sheet has no rows
+func readSheetRows(sim Sim, sheet string) ([][]string, error) {
+	if lister, ok := sim.(SheetLister); ok && !containsString(lister.GetSheetList(), sheet) {
+		return nil, nil
+	}
+
+	reader, ok := sim.(RowsReader)
+	if !ok {
+		return nil, fmt.Errorf("sim can't be read by rows")
+	}
+
+	rows, err := reader.GetRows(sheet)
+	if err != nil {
+		return nil, WrapError(err, "error reading sheet "+sheet)
+	}
+
+	return rows, nil
+}
+
+// rowLabel is the closest text to the left of the cell, the name of a constant
+func rowLabel(row, fallback []string, col int) string {
+	for _, cells := range [][]string{row, fallback} {
+		for i := col - 1; i >= 0; i-- {
+			if value := strings.TrimSpace(valueAt(cells, i)); value != "" {
+				return value
+			}
+		}
+	}
+
+	return ""
+}
+
+// headerLabel joins the header cells above the column, it falls back to the layout name
+func headerLabel(sim Sim, layout *Layout, sheet string, entry layoutEntry) (string, error) {
+	parts := []string{}
+	for row := 1; row < layout.FirstHourRow; row++ {
+		value, err := sim.GetCellValue(sheet, fmt.Sprintf("%s%d", entry.Column, row))
+		if err != nil {
+			return "", WrapError(err, "error reading header")
+		}
+
+		value = strings.Join(strings.Fields(value), " ")
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+
+	if len(parts) > 0 {
+		return strings.Join(parts, " / "), nil
+	}
+	if entry.Label != "" {
+		return entry.Label, nil
+	}
+
+	return strings.TrimSpace(entry.Name), nil
+}
+
+func rowAt(rows [][]string, i int) []string {
+	if i < len(rows) {
+		return rows[i]
+	}
+	return nil
+}
+
+func valueAt(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// WriteDiff writes the changes grouped by sheet and hour as text or JSON
+func WriteDiff(w io.Writer, diff *SimDiff, format string) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+
+	if len(diff.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "No changes between %s and %s\n", diff.Old, diff.New)
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", diff.Old, diff.New))
+
+	group := ""
+	for _, change := range diff.Changes {
+		title := change.Sheet
+		if change.Hour > 0 {
+			title = fmt.Sprintf("%s, hour %d", change.Sheet, change.Hour)
+		}
+		if title != group {
+			group = title
+			sb.WriteString("\n" + title + "\n")
+		}
+
+		name := change.Cell
+		if change.Label != "" {
+			name += " " + change.Label
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %q -> %q\n", name, change.Old, change.New))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,74 @@
+package sim
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func newTestGridSim(cells map[string]map[string]string) *gridSim {
+	grid := newGridSim()
+	for _, sheet := range mustDefaultLayout().sheets() {
+		grid.addSheet(sheet)
+	}
+	for sheet, values := range cells {
+		grid.addSheet(sheet)
+		for cell, value := range values {
+			col, row, _ := excelize.CellNameToCoordinates(cell)
+			grid.setCell(sheet, row, col, value)
+		}
+	}
+	return grid
+}
+
+func TestDiffSims(t *testing.T) {
+	layout := mustDefaultLayout()
+
+	oldSim := newTestGridSim(map[string]map[string]string{
+		Constants: {"A1": "Spy cost", "B1": "500", "A2": "Wizard cost", "B2": "1000"},
+		Military:  {"AX2": "Satyr", "AX6": "3", "Y4": "35"},
+	})
+	newSim := newTestGridSim(map[string]map[string]string{
+		Constants: {"A1": "Spy cost", "B1": "550", "A2": "Wizard cost", "B2": "1000", "A3": "Tax", "B3": "2.7"},
+		Military:  {"AX2": "Satyr", "AX6": "5", "Y4": "35", "Y10": "40"},
+	})
+
+	diff, err := DiffSims(oldSim, layout, newSim, layout)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	diff.Old, diff.New = "old.xlsm", "new.xlsm"
+
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, diff, FormatText); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "--- old.xlsm\n+++ new.xlsm\n" +
+		"\nConstants\n" +
+		"  B1 Spy cost: \"500\" -> \"550\"\n" +
+		"  A3: \"\" -> \"Tax\"\n" +
+		"  B3 Tax: \"\" -> \"2.7\"\n" +
+		"\nMilitary, hour 3\n" +
+		"  AX6 Satyr: \"3\" -> \"5\"\n" +
+		"\nMilitary, hour 7\n" +
+		"  Y10 Draft Rate: \"\" -> \"40\"\n"
+
+	if buf.String() != expected {
+		t.Errorf("Incorrect diff:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+func TestDiffSimsWithoutChanges(t *testing.T) {
+	layout := mustDefaultLayout()
+	sim := newTestGridSim(map[string]map[string]string{Military: {"AX6": "3"}})
+
+	diff, err := DiffSims(sim, layout, sim, layout)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(diff.Changes) != 0 {
+		t.Errorf("Expected no changes, got %v", diff.Changes)
+	}
+}
@@ -0,0 +1,228 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Extra columns scanned to the right of the last column a sheet uses in the layout
+const discoverColumnsMargin = 26
+
+var discoverSheets = []string{
+	Overview, Production, Construction, Explore, Rezone, Military, Magic, Techs, Imps,
+}
+
+type missingHeader struct {
+	Sheet       string
+	Label       string
+	Name        string
+	Column      string
+	Unconfirmed bool
+}
+
+// HeaderError lists labels of the layout that are not found in the sim headers.
+// Commands stop on it unless all the missing labels are unconfirmed.
+type HeaderError struct {
+	Missing []missingHeader
+	Found   map[string][]string
+}
+
+func (e *HeaderError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("sim headers don't match the layout:\n")
+
+	sheets := []string{}
+	for _, missing := range e.Missing {
+		sb.WriteString(fmt.Sprintf("  %s: expected %q for %s", missing.Sheet, missing.Label, missing.Name))
+		if missing.Unconfirmed {
+			sb.WriteString(fmt.Sprintf(", unconfirmed label, column %s is read", missing.Column))
+		}
+		sb.WriteString("\n")
+		if len(sheets) == 0 || sheets[len(sheets)-1] != missing.Sheet {
+			sheets = append(sheets, missing.Sheet)
+		}
+	}
+
+	for _, sheet := range sheets {
+		sb.WriteString(fmt.Sprintf("  %s headers found: %s\n", sheet, strings.Join(e.Found[sheet], ", ")))
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Confirmed reports whether a label confirmed in the sim is missing
+func (e *HeaderError) Confirmed() bool {
+	for _, missing := range e.Missing {
+		if !missing.Unconfirmed {
+			return true
+		}
+	}
+	return false
+}
+
+type headerCell struct {
+	label  string
+	text   string
+	column int
+}
+
+// Discover returns a copy of the layout with the columns of labelled fields resolved by
+// the header rows of the sim, the layout itself is not changed. When a label is found
+// more than once the closest one to the layout column is used, so sections of the same
+// sheet with repeated labels (Farms to build and to destroy) are kept apart.
+// Labels missing in the sim keep their layout column, the copy is returned together
+// with a *HeaderError listing them, see discoverLayout for how commands handle it.
+func (l *Layout) Discover(sim Sim) (*Layout, error) {
+	discovered := l.clone()
+	headers := make(map[string][]headerCell)
+	claimed := make(map[string]bool)
+	headerErr := &HeaderError{Found: make(map[string][]string)}
+
+	for _, sheet := range discoverSheets {
+		cells, err := l.readHeaders(sim, sheet)
+		if err != nil {
+			return nil, err
+		}
+		headers[sheet] = cells
+	}
+
+	resolve := func(sheet, label, column, name string, unconfirmed bool) string {
+		cells, ok := headers[sheet]
+		if !ok {
+			return column
+		}
+
+		current, _ := excelize.ColumnNameToNumber(column)
+		best := -1
+		for i, cell := range cells {
+			if cell.label != normalizeLabel(label) || claimed[fmt.Sprintf("%s!%d", sheet, cell.column)] {
+				continue
+			}
+			if best == -1 || absInt(cell.column-current) < absInt(cells[best].column-current) {
+				best = i
+			}
+		}
+
+		if best == -1 {
+			headerErr.Missing = append(headerErr.Missing, missingHeader{
+				Sheet: sheet, Label: label, Name: name, Column: column, Unconfirmed: unconfirmed,
+			})
+			return column
+		}
+
+		claimed[fmt.Sprintf("%s!%d", sheet, cells[best].column)] = true
+		resolved, _ := excelize.ColumnNumberToName(cells[best].column)
+		return resolved
+	}
+
+	for _, groupName := range layoutGroups() {
+		group := discovered.Groups[groupName]
+		for i, col := range group.Columns {
+			if col.Label == "" {
+				continue
+			}
+			group.Columns[i].Column = resolve(group.Sheet, col.Label, col.Column, groupName+" "+col.Name, group.Unconfirmed || col.Unconfirmed)
+		}
+	}
+
+	for _, name := range discovered.fieldNames() {
+		field := discovered.Fields[name]
+		if field.Label == "" || field.Column == "" {
+			continue
+		}
+		field.Column = resolve(field.Sheet, field.Label, field.Column, name, field.Unconfirmed)
+		discovered.Fields[name] = field
+	}
+
+	if len(headerErr.Missing) > 0 {
+		sort.SliceStable(headerErr.Missing, func(i, j int) bool {
+			return headerErr.Missing[i].Sheet < headerErr.Missing[j].Sheet
+		})
+		for sheet, cells := range headers {
+			for _, cell := range cells {
+				name, _ := excelize.ColumnNumberToName(cell.column)
+				headerErr.Found[sheet] = append(headerErr.Found[sheet], fmt.Sprintf("%s=%q", name, cell.text))
+			}
+		}
+		return discovered, headerErr
+	}
+
+	return discovered, nil
+}
+
+// discoverLayout returns the layout discovered in the sim at simPath. Missing labels
+// stop the command and nil is returned with the error, when all of them are unconfirmed
+// a warning is printed and their layout columns are read. SkipDiscovery reads the
+// layout columns as they are instead.
+func discoverLayout(layout *Layout, sim Sim, simPath string) (*Layout, error) {
+	discovered, err := layout.Discover(sim)
+
+	var headerErr *HeaderError
+	if errors.As(err, &headerErr) && !headerErr.Confirmed() {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", simPath, err)
+		return discovered, nil
+	}
+	if err != nil {
+		return nil, WrapError(err, simPath)
+	}
+
+	return discovered, nil
+}
+
+// readHeaders reads all non empty cells above the first hour row of a sheet
+func (l *Layout) readHeaders(sim Sim, sheet string) ([]headerCell, error) {
+	lastColumn := l.lastColumn(sheet)
+	if lastColumn == 0 {
+		return nil, nil
+	}
+
+	cells := []headerCell{}
+	for row := 1; row < l.FirstHourRow; row++ {
+		for col := 1; col <= lastColumn+discoverColumnsMargin; col++ {
+			cell, _ := excelize.CoordinatesToCellName(col, row)
+			value, err := sim.GetCellValue(sheet, cell)
+			if err != nil {
+				return nil, WrapError(err, fmt.Sprintf("error reading header %s!%s", sheet, cell))
+			}
+
+			label := normalizeLabel(value)
+			if label == "" {
+				continue
+			}
+			cells = append(cells, headerCell{label: label, text: strings.TrimSpace(value), column: col})
+		}
+	}
+
+	return cells, nil
+}
+
+// lastColumn returns the right-most column number of a sheet used by labelled entries
+func (l *Layout) lastColumn(sheet string) int {
+	last := 0
+	for _, entry := range l.labelledEntries() {
+		if entry.Sheet != sheet {
+			continue
+		}
+		if number, err := excelize.ColumnNameToNumber(entry.Column); err == nil && number > last {
+			last = number
+		}
+	}
+
+	return last
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,148 @@
+package sim
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newDiscoverLayout() *Layout {
+	return &Layout{
+		HeaderRow:    2,
+		FirstHourRow: 4,
+		Fields: map[string]LayoutField{
+			"construction_platinum_cost": {Sheet: Construction, Column: "E", Label: "Plat Cost"},
+			"date":                       {Sheet: Overview, Cell: "B15"},
+		},
+		Groups: map[string]LayoutGroup{
+			"construction": {Sheet: Construction, Columns: []LayoutColumn{
+				{Name: "Farms", Label: "Farms", Column: "B"},
+				{Name: "Homes", Label: "Homes", Column: "C"},
+			}},
+			"destruction": {Sheet: Construction, Columns: []LayoutColumn{
+				{Name: "Farms", Label: "Farms", Column: "G"},
+			}},
+		},
+	}
+}
+
+func TestLayoutDiscover(t *testing.T) {
+	mockSim := &SimMock{
+		AllowMissing: true,
+		Data: map[string]map[string]string{
+			Construction: {
+				// A column is inserted before every section
+				"A1": "Construct", "C2": "Farms", "D2": " homes ",
+				"F2": "Plat Cost",
+				"G1": "Destroy", "H2": "Farms",
+			},
+		},
+	}
+
+	original := newDiscoverLayout()
+	layout, err := original.Discover(mockSim)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"construction Farms": "C",
+		"construction Homes": "D",
+		"destruction Farms":  "H",
+		"plat cost":          "F",
+	}
+	got := map[string]string{
+		"construction Farms": layout.Groups["construction"].Columns[0].Column,
+		"construction Homes": layout.Groups["construction"].Columns[1].Column,
+		"destruction Farms":  layout.Groups["destruction"].Columns[0].Column,
+		"plat cost":          layout.Fields["construction_platinum_cost"].Column,
+	}
+
+	for name, column := range expected {
+		if got[name] != column {
+			t.Errorf("Incorrect column for %s: got %q, want %q", name, got[name], column)
+		}
+	}
+
+	if layout.Fields["date"].Cell != "B15" {
+		t.Errorf("Fixed cell should not change: got %+v", layout.Fields["date"])
+	}
+
+	if original.Groups["construction"].Columns[0].Column != "B" || original.Fields["construction_platinum_cost"].Column != "E" {
+		t.Errorf("Discover should not change the layout: %+v", original)
+	}
+}
+
+func TestLayoutDiscoverMissingHeaders(t *testing.T) {
+	mockSim := &SimMock{
+		AllowMissing: true,
+		Data: map[string]map[string]string{
+			Construction: {"B2": "Farms", "C2": "Homes", "G2": "Farms"},
+		},
+	}
+
+	layout, err := newDiscoverLayout().Discover(mockSim)
+
+	var headerErr *HeaderError
+	if !errors.As(err, &headerErr) {
+		t.Fatalf("Expected HeaderError, got %v", err)
+	}
+
+	// The missing label is read from its layout column, the found ones are resolved
+	if layout == nil || layout.Fields["construction_platinum_cost"].Column != "E" || layout.Groups["construction"].Columns[1].Column != "C" {
+		t.Fatalf("Expected the layout columns for missing labels, got %+v", layout)
+	}
+
+	if len(headerErr.Missing) != 1 || headerErr.Missing[0].Label != "Plat Cost" {
+		t.Errorf("Incorrect missing headers: %+v", headerErr.Missing)
+	}
+
+	for _, expected := range []string{`expected "Plat Cost" for construction_platinum_cost`, `B="Farms"`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Error %q should contain %q", err, expected)
+		}
+	}
+
+	// Commands stop on missing labels and name the sim
+	if _, err := discoverLayout(newDiscoverLayout(), mockSim, "reordered.xlsm"); !errors.As(err, &headerErr) || !strings.HasPrefix(err.Error(), "reordered.xlsm: ") {
+		t.Errorf("Expected HeaderError for reordered.xlsm, got %v", err)
+	}
+}
+
+func TestDiscoverLayoutUnconfirmedLabels(t *testing.T) {
+	mockSim := &SimMock{
+		AllowMissing: true,
+		Data: map[string]map[string]string{
+			Construction: {"C2": "Farms", "D2": "Homes", "H2": "Farms"},
+		},
+	}
+
+	layout := newDiscoverLayout()
+	field := layout.Fields["construction_platinum_cost"]
+	field.Unconfirmed = true
+	layout.Fields["construction_platinum_cost"] = field
+
+	// An unconfirmed label keeps its layout column with a warning, the others are resolved
+	discovered, err := discoverLayout(layout, mockSim, "sim.xlsm")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if discovered.Fields["construction_platinum_cost"].Column != "E" || discovered.Groups["construction"].Columns[0].Column != "C" {
+		t.Errorf("Incorrect columns: %+v", discovered)
+	}
+
+	// Labels of the default layout are not confirmed against the upstream sim yet,
+	// a sim without them is read from the layout columns
+	defaultLayout, err := DefaultLayout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	discovered, err = discoverLayout(defaultLayout, &SimMock{AllowMissing: true, Data: map[string]map[string]string{}}, "sim.xlsm")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(discovered, defaultLayout) {
+		t.Errorf("Expected the layout columns, got %+v", discovered)
+	}
+}
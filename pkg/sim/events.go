@@ -0,0 +1,179 @@
+package sim
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is an action of a protection hour read from the sim.
+type Event interface {
+	EventType() string
+}
+
+// Amount is a named quantity: units, buildings, lands or resources
+type Amount struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// HourLog holds the events of a protection hour in the order they are imported.
+type HourLog struct {
+	Hour      int
+	LocalTime time.Time
+	DomTime   time.Time
+	Events    []Event
+}
+
+type DraftRateChanged struct {
+	Rate string
+}
+
+type UnitsReleased struct {
+	Units    []Amount
+	Draftees int
+}
+
+type SpellCast struct {
+	Spell string
+	Mana  int
+}
+
+type TechUnlocked struct {
+	Tech string
+}
+
+type DailyPlatinum struct {
+	Platinum int
+}
+
+// ResourcesTraded holds signed amounts, negative ones are traded away
+type ResourcesTraded struct {
+	Resources []Amount
+}
+
+type ExplorationStarted struct {
+	Lands        []Amount
+	PlatinumCost int
+	DrafteeCost  int
+}
+
+type DailyLand struct {
+	Acres int
+	Land  string
+}
+
+type BuildingsDestroyed struct {
+	Buildings []Amount
+}
+
+type Rezoned struct {
+	Lands        []Amount
+	PlatinumCost int
+}
+
+type ConstructionStarted struct {
+	Buildings    []Amount
+	PlatinumCost int
+	LumberCost   int
+}
+
+type UnitsTrained struct {
+	Units        []Amount
+	PlatinumCost int
+	OreCost      int
+	DrafteeCost  int
+	SpyCost      int
+	WizardCost   int
+}
+
+type Invested struct {
+	Amount      int
+	Resource    string
+	Improvement string
+}
+
+func (DraftRateChanged) EventType() string    { return "DraftRateChanged" }
+func (UnitsReleased) EventType() string       { return "UnitsReleased" }
+func (SpellCast) EventType() string           { return "SpellCast" }
+func (TechUnlocked) EventType() string        { return "TechUnlocked" }
+func (DailyPlatinum) EventType() string       { return "DailyPlatinum" }
+func (ResourcesTraded) EventType() string     { return "ResourcesTraded" }
+func (ExplorationStarted) EventType() string  { return "ExplorationStarted" }
+func (DailyLand) EventType() string           { return "DailyLand" }
+func (BuildingsDestroyed) EventType() string  { return "BuildingsDestroyed" }
+func (Rezoned) EventType() string             { return "Rezoned" }
+func (ConstructionStarted) EventType() string { return "ConstructionStarted" }
+func (UnitsTrained) EventType() string        { return "UnitsTrained" }
+func (Invested) EventType() string            { return "Invested" }
+
+// amountsData adds amounts to the result data with keys used by the log parser
+func amountsData(data ActionResultData, amounts []Amount) ActionResultData {
+	for _, amount := range amounts {
+		data[resultKey(amount.Name)] += amount.Value
+	}
+
+	return data
+}
+
+// EventResults converts an event to the actions parsed from its import log lines.
+func EventResults(event Event) []ActionResult {
+	switch e := event.(type) {
+	case DraftRateChanged:
+		rate, _ := strconv.ParseFloat(strings.TrimSuffix(e.Rate, "%"), 64)
+		return []ActionResult{{Type: DRAFTRATE, Data: ActionResultData{"value": FloatToInt(rate)}}}
+	case UnitsReleased:
+		results := []ActionResult{}
+		if len(e.Units) > 0 {
+			results = append(results, ActionResult{Type: RELEASE, Data: amountsData(ActionResultData{}, e.Units)})
+		}
+		if e.Draftees > 0 {
+			results = append(results, ActionResult{Type: RELEASE, Data: ActionResultData{resultKey("draftees"): e.Draftees}})
+		}
+		return results
+	case SpellCast:
+		return []ActionResult{{Type: MAGIC, Name: e.Spell, Data: ActionResultData{"mana": e.Mana}}}
+	case TechUnlocked:
+		return []ActionResult{{Type: TECH, Name: e.Tech, Data: ActionResultData{}}}
+	case DailyPlatinum:
+		return []ActionResult{{Type: DAILY, Data: ActionResultData{"platinum": e.Platinum}}}
+	case ResourcesTraded:
+		data := ActionResultData{}
+		for _, resource := range e.Resources {
+			if resource.Value != 0 {
+				data[resultKey(resource.Name)] += resource.Value
+			}
+		}
+		return []ActionResult{{Type: BANK, Data: data}}
+	case ExplorationStarted:
+		data := amountsData(ActionResultData{}, e.Lands)
+		data["cost_platinum"] = e.PlatinumCost
+		data["cost_draftees"] = e.DrafteeCost
+		return []ActionResult{{Type: EXPLORE, Data: data}}
+	case DailyLand:
+		return []ActionResult{{Type: DAILY, Data: ActionResultData{resultKey(e.Land): e.Acres}}}
+	case BuildingsDestroyed:
+		return []ActionResult{{Type: DESTRUCTION, Data: amountsData(ActionResultData{}, e.Buildings)}}
+	case Rezoned:
+		data := amountsData(ActionResultData{}, e.Lands)
+		data["cost_platinum"] = e.PlatinumCost
+		return []ActionResult{{Type: REZONE, Data: data}}
+	case ConstructionStarted:
+		data := amountsData(ActionResultData{}, e.Buildings)
+		data["cost_platinum"] = e.PlatinumCost
+		data["cost_lumber"] = e.LumberCost
+		return []ActionResult{{Type: CONSTRUCTION, Data: data}}
+	case UnitsTrained:
+		data := amountsData(ActionResultData{}, e.Units)
+		data["cost_platinum"] = e.PlatinumCost
+		data["cost_ore"] = e.OreCost
+		data["cost_draftees"] = e.DrafteeCost
+		data["cost_spies"] = e.SpyCost
+		data["cost_wizards"] = e.WizardCost
+		return []ActionResult{{Type: TRAIN, Data: data}}
+	case Invested:
+		return []ActionResult{{Type: INVEST, Name: e.Improvement, Data: ActionResultData{e.Resource: e.Amount}}}
+	}
+
+	return nil
+}
@@ -0,0 +1,277 @@
+package sim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/rxx/od_tools/data"
+	"github.com/xuri/excelize/v2"
+)
+
+// Rows of the Constants sheet (columns A and B) included in the fingerprint
+const fingerprintConstantsRows = 120
+
+// Header cells of the sim sheets included in the fingerprint, the same for every layout
+// so a workbook gets the same fingerprint whatever layouts it's compared to
+const (
+	fingerprintHeaderRows    = 3
+	fingerprintHeaderColumns = 104 // A to CZ
+)
+
+// SheetLister is implemented by sims able to list their sheets, like excelize.File.
+type SheetLister interface {
+	GetSheetList() []string
+}
+
+// LayoutMatch tells how well a sim matches a layout revision. Fingerprint is the
+// fingerprint of the key header cells of the sim, the cells labelled in the layout,
+// it equals LayoutFingerprint of the layout when all of them match.
+type LayoutMatch struct {
+	Revision      string
+	Layout        *Layout
+	Matched       int
+	Total         int
+	Fingerprint   string
+	MissingSheets []string
+	Mismatches    []string
+}
+
+func (m LayoutMatch) Supported() bool {
+	return len(m.MissingSheets) == 0 && m.Matched == m.Total
+}
+
+// VersionCheck is a fingerprint of a sim workbook and the layouts it was compared to.
+type VersionCheck struct {
+	Fingerprint   string
+	ConstantsHash string
+	// HeaderHashes are the hashes of the header cells of every sim sheet
+	HeaderHashes map[string]string
+	Sheets       []string
+	Matches      []LayoutMatch
+	// Revision is the known revision closest to the sim, set by MatchRevisions
+	Revision *RevisionMatch
+}
+
+// Best returns the closest layout, it is supported only when Supported reports true.
+func (v *VersionCheck) Best() (LayoutMatch, bool) {
+	if len(v.Matches) == 0 {
+		return LayoutMatch{}, false
+	}
+
+	return v.Matches[0], v.Matches[0].Supported()
+}
+
+// KnownLayouts returns all layout revisions embedded in data/layouts.
+func KnownLayouts() ([]*Layout, error) {
+	files, err := fs.Glob(data.FS, "layouts/*.yml")
+	if err != nil {
+		return nil, WrapError(err, "error listing layouts")
+	}
+	sort.Strings(files)
+
+	layouts := []*Layout{}
+	for _, file := range files {
+		content, err := data.FS.ReadFile(file)
+		if err != nil {
+			return nil, WrapError(err, "error reading layout "+file)
+		}
+
+		layout, err := ParseLayout(content)
+		if err != nil {
+			return nil, WrapError(err, "error in layout "+file)
+		}
+		if layout.Revision == "" {
+			layout.Revision = strings.TrimSuffix(path.Base(file), ".yml")
+		}
+
+		layouts = append(layouts, layout)
+	}
+
+	return layouts, nil
+}
+
+// CheckVersion fingerprints a sim by its sheet names, a fixed set of header cells
+// and the Constants values, and compares it to the layouts.
+func CheckVersion(sim Sim, layouts []*Layout) (*VersionCheck, error) {
+	var err error
+	check := &VersionCheck{}
+
+	if lister, ok := sim.(SheetLister); ok {
+		check.Sheets = lister.GetSheetList()
+	}
+
+	for _, layout := range layouts {
+		match, err := matchLayout(sim, layout, check.Sheets)
+		if err != nil {
+			return nil, err
+		}
+		check.Matches = append(check.Matches, match)
+	}
+
+	sort.SliceStable(check.Matches, func(i, j int) bool {
+		return check.Matches[i].score() > check.Matches[j].score()
+	})
+
+	constantsHash := sha256.New()
+	if len(check.Sheets) == 0 || containsString(check.Sheets, Constants) {
+		for row := 1; row <= fingerprintConstantsRows; row++ {
+			for _, col := range []string{"A", "B"} {
+				value, err := sim.GetCellValue(Constants, fmt.Sprintf("%s%d", col, row))
+				if err != nil {
+					return nil, WrapError(err, "error reading constants")
+				}
+				constantsHash.Write([]byte(strings.TrimSpace(value) + "\n"))
+			}
+		}
+	}
+
+	check.ConstantsHash = hex.EncodeToString(constantsHash.Sum(nil))[:12]
+
+	if check.HeaderHashes, err = hashHeaders(sim, check.Sheets); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(strings.Join(check.Sheets, "\n")))
+	for _, sheet := range discoverSheets {
+		hash.Write([]byte(check.HeaderHashes[sheet] + "\n"))
+	}
+	hash.Write([]byte(check.ConstantsHash))
+	check.Fingerprint = hex.EncodeToString(hash.Sum(nil))[:12]
+
+	return check, nil
+}
+
+// hashHeaders hashes the header rows of every sim sheet, sheets missing in the sim are skipped
+func hashHeaders(sim Sim, sheets []string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	for _, sheet := range discoverSheets {
+		if len(sheets) > 0 && !containsString(sheets, sheet) {
+			continue
+		}
+
+		hash := sha256.New()
+		for row := 1; row <= fingerprintHeaderRows; row++ {
+			for col := 1; col <= fingerprintHeaderColumns; col++ {
+				cell, err := excelize.CoordinatesToCellName(col, row)
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := sim.GetCellValue(sheet, cell)
+				if err != nil {
+					return nil, WrapError(err, fmt.Sprintf("error reading header %s!%s", sheet, cell))
+				}
+				hash.Write([]byte(strings.TrimSpace(value) + "\n"))
+			}
+		}
+		hashes[sheet] = hex.EncodeToString(hash.Sum(nil))[:12]
+	}
+
+	return hashes, nil
+}
+
+func (m LayoutMatch) score() float64 {
+	if m.Total == 0 {
+		return 0
+	}
+
+	return float64(m.Matched)/float64(m.Total) - float64(len(m.MissingSheets))
+}
+
+// LayoutFingerprint is the fingerprint of the key header cells of a sim read with the
+// layout as it is, sheet names and the labels at their columns.
+func LayoutFingerprint(layout *Layout) string {
+	keys := []string{}
+	for _, entry := range layout.labelledEntries() {
+		keys = append(keys, headerKey(entry, normalizeLabel(entry.Label)))
+	}
+
+	return keyFingerprint(layout.sheets(), keys)
+}
+
+func headerKey(entry layoutEntry, header string) string {
+	return fmt.Sprintf("%s!%s=%s", entry.Sheet, entry.Column, header)
+}
+
+func keyFingerprint(sheets, keys []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(strings.Join(sheets, "\n") + "\n\n"))
+	hash.Write([]byte(strings.Join(keys, "\n")))
+
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+func matchLayout(sim Sim, layout *Layout, sheets []string) (LayoutMatch, error) {
+	match := LayoutMatch{Revision: layout.Revision, Layout: layout}
+
+	missing := make(map[string]bool)
+	if len(sheets) > 0 {
+		for _, sheet := range layout.sheets() {
+			if !containsString(sheets, sheet) {
+				missing[sheet] = true
+				match.MissingSheets = append(match.MissingSheets, sheet)
+			}
+		}
+	}
+
+	presentSheets := []string{}
+	for _, sheet := range layout.sheets() {
+		if !missing[sheet] {
+			presentSheets = append(presentSheets, sheet)
+		}
+	}
+
+	keys := []string{}
+	for _, entry := range layout.labelledEntries() {
+		match.Total++
+		if missing[entry.Sheet] {
+			keys = append(keys, headerKey(entry, ""))
+			continue
+		}
+
+		found := false
+		headers := []string{}
+		for row := 1; row < layout.FirstHourRow; row++ {
+			cell := fmt.Sprintf("%s%d", entry.Column, row)
+			value, err := sim.GetCellValue(entry.Sheet, cell)
+			if err != nil {
+				return match, WrapError(err, fmt.Sprintf("error reading header %s!%s", entry.Sheet, cell))
+			}
+
+			label := normalizeLabel(value)
+			if label == normalizeLabel(entry.Label) {
+				found = true
+			}
+			if label != "" {
+				headers = append(headers, label)
+			}
+		}
+
+		if found {
+			match.Matched++
+			keys = append(keys, headerKey(entry, normalizeLabel(entry.Label)))
+		} else {
+			keys = append(keys, headerKey(entry, strings.Join(headers, "|")))
+			match.Mismatches = append(match.Mismatches,
+				fmt.Sprintf("%s!%s: expected %q for %s", entry.Sheet, entry.Column, entry.Label, entry.Name))
+		}
+	}
+	match.Fingerprint = keyFingerprint(presentSheets, keys)
+
+	return match, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheckVersion(t *testing.T) {
+	simData := map[string]map[string]string{
+		Construction: {"B2": "Farms", "C2": "Homes", "E2": "Plat Cost", "G2": "Farms"},
+		Constants:    {"A75": "Gaia's Watch", "B75": "2"},
+	}
+
+	testCases := []struct {
+		name      string
+		override  map[string]map[string]string
+		sheets    []string
+		supported bool
+		expected  string
+	}{
+		{
+			name:      "Supported",
+			sheets:    []string{Overview, Construction, Constants},
+			supported: true,
+			expected:  "test: supported (4 of 4 headers",
+		},
+		{
+			name:      "Moved Column",
+			override:  map[string]map[string]string{Construction: {"C2": "", "D2": "Homes"}},
+			sheets:    []string{Overview, Construction, Constants},
+			supported: false,
+			expected:  `Construction!C: expected "Homes" for construction Homes`,
+		},
+		{
+			name:      "Missing Sheet",
+			sheets:    []string{Construction, Constants},
+			supported: false,
+			expected:  "missing sheets: Overview",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSim := &SimMock{
+				Data:         deepCopyAndMergeMaps(simData, tc.override),
+				AllowMissing: true,
+				Sheets:       tc.sheets,
+			}
+			layout := newDiscoverLayout()
+			layout.Revision = "test"
+
+			check, err := CheckVersion(mockSim, []*Layout{layout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if _, ok := check.Best(); ok != tc.supported {
+				t.Errorf("Incorrect support: got %v, want %v", ok, tc.supported)
+			}
+
+			if report := check.Report(); !strings.Contains(report, tc.expected) {
+				t.Errorf("Report %q should contain %q", report, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCheckVersionFingerprint(t *testing.T) {
+	check := func(constant string) *VersionCheck {
+		mockSim := &SimMock{
+			Data:         map[string]map[string]string{Constants: {"B75": constant}},
+			AllowMissing: true,
+		}
+		check, err := CheckVersion(mockSim, []*Layout{newDiscoverLayout()})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return check
+	}
+
+	first, same, changed := check("2"), check("2"), check("2.5")
+
+	if first.Fingerprint != same.Fingerprint {
+		t.Errorf("Fingerprint should be stable: %s != %s", first.Fingerprint, same.Fingerprint)
+	}
+	if first.ConstantsHash == changed.ConstantsHash || first.Fingerprint == changed.Fingerprint {
+		t.Errorf("Fingerprint should change with constants")
+	}
+}
+
+func TestCheckVersionFingerprintIgnoresLayouts(t *testing.T) {
+	mockSim := &SimMock{
+		Data: map[string]map[string]string{
+			Construction: {"B2": "Farms", "C2": "Homes"},
+			Constants:    {"B75": "2"},
+		},
+		AllowMissing: true,
+	}
+
+	own, err := CheckVersion(mockSim, []*Layout{newDiscoverLayout()})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	known, err := KnownLayouts()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	all, err := CheckVersion(mockSim, append(known, newDiscoverLayout()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if own.Fingerprint != all.Fingerprint {
+		t.Errorf("Fingerprint should not depend on the layouts: %s != %s", own.Fingerprint, all.Fingerprint)
+	}
+
+	mockSim.Data[Construction]["C2"] = "Towers"
+	changed, err := CheckVersion(mockSim, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed.Fingerprint == own.Fingerprint {
+		t.Error("Fingerprint should change with the headers")
+	}
+}
+
+func TestCheckVersionRevisions(t *testing.T) {
+	mockSim := &SimMock{
+		Data: map[string]map[string]string{
+			Construction: {"B2": "Farms", "C2": "Homes", "E2": "Plat Cost", "G2": "Farms"},
+			Constants:    {"B75": "2"},
+		},
+		AllowMissing: true,
+		Sheets:       []string{Overview, Construction, Constants},
+	}
+	layout := newDiscoverLayout()
+	layout.Revision = "test"
+
+	known, err := CheckVersion(mockSim, []*Layout{layout})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if known.Matches[0].Fingerprint != LayoutFingerprint(layout) {
+		t.Fatalf("A sim with all the headers should have the fingerprint of the layout: %s != %s",
+			known.Matches[0].Fingerprint, LayoutFingerprint(layout))
+	}
+
+	revisions := []Revision{
+		{Name: "r1", Layout: "old", Fingerprint: "000000000000"},
+		{Name: "r2", Layout: "test", Fingerprint: LayoutFingerprint(layout), Constants: "111111111111"},
+		{Name: "r3", Layout: "test", Fingerprint: LayoutFingerprint(layout)},
+	}
+
+	known.MatchRevisions(revisions)
+	if known.Revision == nil || !known.Revision.Exact() || known.Revision.Revision.Name != "r3" {
+		t.Fatalf("Expected exact revision r3, got %+v", known.Revision)
+	}
+	if report := known.Report(); !strings.Contains(report, "Revision:    r3 (layout test)") || strings.Contains(report, "new revision") {
+		t.Errorf("Incorrect report of a known revision:\n%s", report)
+	}
+
+	// A moved header, r3 is still the closest revision
+	mockSim.Data[Construction]["C2"] = "Towers"
+	changed, err := CheckVersion(mockSim, []*Layout{layout})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	changed.MatchRevisions(revisions)
+	if changed.Revision == nil || changed.Revision.Exact() || changed.Revision.Revision.Name != "r3" {
+		t.Fatalf("Expected closest revision r3, got %+v", changed.Revision)
+	}
+	if report := changed.Report(); !strings.Contains(report, "unknown, closest is r3 (3 of 4 parts") {
+		t.Errorf("Report should name the closest revision:\n%s", report)
+	}
+
+	// A supported layout without a revision prints the entry to add
+	mockSim.Data[Construction]["C2"] = "Homes"
+	unlisted, err := CheckVersion(mockSim, []*Layout{layout})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	unlisted.MatchRevisions(revisions[:1])
+	if report := unlisted.Report(); !strings.Contains(report, "fingerprint: "+LayoutFingerprint(layout)) {
+		t.Errorf("Report should print the revision entry:\n%s", report)
+	}
+}
+
+func TestKnownRevisions(t *testing.T) {
+	revisions, err := KnownRevisions()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	layouts, err := KnownLayouts()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, revision := range revisions {
+		found := false
+		for _, layout := range layouts {
+			found = found || layout.Revision == revision.Layout
+		}
+		if !found {
+			t.Errorf("Revision %s uses unknown layout %q", revision.Name, revision.Layout)
+		}
+	}
+
+	if _, err := ParseRevisions([]byte("revisions:\n  - name: r1\n")); err == nil {
+		t.Error("Expected an error for a revision without layout and fingerprint")
+	}
+}
+
+func TestDefaultLayoutRevision(t *testing.T) {
+	layout, err := DefaultLayout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := KnownRevisions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A sim with the headers of the default layout is the revision it was written for
+	simData := map[string]map[string]string{}
+	for _, entry := range layout.labelledEntries() {
+		if simData[entry.Sheet] == nil {
+			simData[entry.Sheet] = map[string]string{}
+		}
+		simData[entry.Sheet][fmt.Sprintf("%s%d", entry.Column, layout.HeaderRow)] = entry.Label
+	}
+
+	check, err := CheckVersion(&SimMock{Data: simData, AllowMissing: true, Sheets: layout.sheets()}, []*Layout{layout})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	check.MatchRevisions(revisions)
+
+	if check.Revision == nil || !check.Revision.Exact() || check.Revision.Revision.Layout != layout.Revision {
+		t.Errorf("The default layout should match a known revision, add it with fingerprint %s: got %+v",
+			LayoutFingerprint(layout), check.Revision)
+	}
+}
@@ -3,6 +3,7 @@ package sim
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -32,69 +33,108 @@ const (
 	MidasTouch     = "Midas Touch"
 	Harmony        = "Harmony"
 
+	// RacialSpell names the racial spell in plans, the log has the spell of the race
 	RacialSpell = "Racial Spell"
 
 	PlatAwardedMult = 4
 	LandBonus       = 20
+	// Hours explored land takes to arrive
+	ExploreHours = 12
 )
 
-var buildingNames = []string{
-	"Homes", "Alchemies", "Farms", "Smithies", "Masonries", "Lumber Yards",
-	"Ore Mines", "Gryphon Nests", "Factories", "Guard Towers", "Barracks",
-	"Shrines", "Towers", "Temples", "Wizard Guilds", "Diamond Mines", "Schools", "Docks",
-}
-
-var exploreLands = map[string]string{
-	"Plains":    "T",
-	"Forest":    "U",
-	"Mountains": "V",
-	"Hills":     "W",
-	"Swamps":    "X",
-	"Caverns":   "Y",
-	"Water":     "Z",
-}
-
-var rezoneLands = map[string]string{
-	"Plains":    "L",
-	"Forest":    "M",
-	"Mountains": "N",
-	"Hills":     "O",
-	"Swamps":    "P",
-	"Caverns":   "Q",
-	"Water":     "R",
-}
-
-type ActionFunc func() (string, error)
+// ActionFunc reads the events of an action for the current hour
+type ActionFunc func() ([]Event, error)
 
 type Sim interface {
 	GetCellValue(sheet, cell string, opts ...excelize.Options) (string, error)
 	Close() error
 }
 
+// GameLogOptions are the generate_log settings passed from the command line.
+type GameLogOptions struct {
+	// From and To are the range of generated protection hours, 0 means the first and the last one
+	From          int
+	To            int
+	LayoutPath    string
+	SkipDiscovery bool
+	// Force generates the log even when the sim has validation errors
+	Force bool
+	// Recalc evaluates formulas instead of using values cached by Excel
+	Recalc bool
+	// Format of the result: text (OpenDominion import log), json or ndjson
+	Format string
+}
+
 type GameLogCmd struct {
 	currentHour int
 	simHour     int
 	simPath     string
 	resultPath  string
+	options     GameLogOptions
+	layout      *Layout
 	sim         Sim
 	// sim     *excelize.File
 	actions []ActionFunc
+	rules   []RuleFunc
+	spells  map[string]SpellData
+	racial  *SpellData
 }
 
-func NewGameLog(path, resultPath string) *GameLogCmd {
+func NewGameLog(path, resultPath string, options GameLogOptions) (*GameLogCmd, error) {
+	if options.Format == "" {
+		options.Format = FormatText
+	}
+	if err := checkFormat(options.Format, FormatText, FormatJSON, FormatNDJSON); err != nil {
+		return nil, err
+	}
+
+	if options.From == 0 {
+		options.From = 1
+	}
+	if options.To == 0 {
+		options.To = LastHour
+	}
+	if options.From < 1 || options.To > LastHour || options.From > options.To {
+		return nil, fmt.Errorf("invalid hour range %d-%d, hours are from 1 to %d", options.From, options.To, LastHour)
+	}
+
+	layout, err := LoadLayout(options.LayoutPath)
+	if err != nil {
+		return nil, err
+	}
+
 	gameLogCmd := &GameLogCmd{
 		simPath:    path,
 		resultPath: resultPath,
+		options:    options,
+		layout:     layout,
 	}
 	gameLogCmd.initActions()
-	gameLogCmd.initSim()
+	gameLogCmd.initRules()
 
-	return gameLogCmd
+	if err := gameLogCmd.initSim(); err != nil {
+		return nil, err
+	}
+
+	// The sim is compared to the layout as it's written, discovery moves the columns
+	// of the layout to the headers found in the sim
+	if err := gameLogCmd.warnUnsupportedVersion(); err != nil {
+		gameLogCmd.sim.Close()
+		return nil, err
+	}
+
+	if !options.SkipDiscovery {
+		if gameLogCmd.layout, err = discoverLayout(layout, gameLogCmd.sim, path); err != nil {
+			gameLogCmd.sim.Close()
+			return nil, err
+		}
+	}
+
+	return gameLogCmd, nil
 }
 
 func (c *GameLogCmd) initActions() {
 	c.actions = []ActionFunc{
-		c.tickAction,
 		c.draftRateAction,
 		c.releaseUnitsAction,
 		c.castMagicSpells,
@@ -111,50 +151,6 @@ func (c *GameLogCmd) initActions() {
 	}
 }
 
-func (c *GameLogCmd) validateSim() error {
-	//	c.draftRateAction // 90 max, 0 min Military#Z4 to Z76
-
-	//	c.releaseUnitsAction // release not more than units exists
-	// Military E, F, G, H, I, J, K, L >= 0
-	//	c.castMagicSpells // cast not more than mana available
-	// Production#K15 >= 0
-	//	c.dailtyPlatinumAction
-	// only once in 24 hour range
-	// 4-27, 25-48, 49-23
-	//	c.tradeResources // all traded resources are valid
-	// - 1 lumber = 0.5 platinum
-	//- 1 lumber = 0.5 ore
-	//- 1 platinum = 0.5 lumber
-	//- 1 platinum = 0.5 ore
-
-	//- 1 gem = 1 food
-	//- 1 gem = 2 ore
-	//- 1 gem = 2 lum
-	//- 1 gem = 2 platinum
-	//	c.exploreAction // not more than plat, draft available
-	// Pipulation L4-76 >= 0
-
-	//	c.dailyLandAction // only once in // 4-27, 25-48, 49-23
-	// c.destroyBuildingsAction // no more than available
-	// Construction >= 0
-	// AY to BQ
-	//	c.rezoneAction,
-	// c.constructionAction // land available
-	// Construction G to M >= 0
-	// Production H to M >= 0
-	//	c.trainUnitsAction //better left more than 400 platinum after training
-	//	c.improvementsAction,
-	return nil
-}
-
-func (c *GameLogCmd) readConst(cell string) (float64, error) {
-	value, err := c.readFloatValue(Constants, cell, "error reading const")
-	if err != nil {
-		return 0, err
-	}
-	return value, nil
-}
-
 func (c *GameLogCmd) wrapHour(cellCol string) string {
 	return c.wrapHourAs(cellCol, c.simHour)
 }
@@ -163,28 +159,107 @@ func (c *GameLogCmd) wrapHourAs(cellCol string, hour int) string {
 	return fmt.Sprintf("%s%d", cellCol, hour)
 }
 
-func (c *GameLogCmd) readLandSize() (int, error) {
-	value, err := c.readIntValue(Explore, c.wrapHour("B"), "error reading land size")
-	if err != nil {
-		return 0, err
+// fieldCell returns the sheet and the cell of a layout field for the current hour
+func (c *GameLogCmd) fieldCell(name string) (string, string) {
+	return c.fieldCellAt(name, c.simHour)
+}
+
+// fieldCellAt returns the sheet and the cell of a layout field at the sim row
+func (c *GameLogCmd) fieldCellAt(name string, row int) (string, string) {
+	field := c.layout.Field(name)
+	if field.Cell != "" {
+		return field.Sheet, field.Cell
 	}
-	return value, nil
+
+	return field.Sheet, c.wrapHourAs(field.Column, row)
 }
 
-// Starting at row 4 because of extra added row (due to uniform table headers)
+func (c *GameLogCmd) readField(name, errorMsg string) (string, error) {
+	sheet, cell := c.fieldCell(name)
+	return c.readValue(sheet, cell, errorMsg)
+}
+
+func (c *GameLogCmd) readIntField(name, errorMsg string) (int, error) {
+	sheet, cell := c.fieldCell(name)
+	return c.readIntValue(sheet, cell, errorMsg)
+}
+
+// Starting at first_hour_row of the layout because of extra added rows (due to uniform table headers)
 func (c *GameLogCmd) setCurrentHour(hr int) {
 	c.currentHour = hr - 1
-	c.simHour = hr + 3
+	c.simHour = c.hourRow(hr)
+}
+
+// hourRow returns the sim row of a protection hour
+func (c *GameLogCmd) hourRow(hr int) int {
+	return hr + c.layout.FirstHourRow - 1
 }
 
-func (c *GameLogCmd) initSim() {
+func (c *GameLogCmd) initSim() error {
 	var err error
 
-	c.sim, err = excelize.OpenFile(c.simPath)
+	c.sim, err = OpenSim(c.simPath)
 	if err != nil {
-		fmt.Println("Error on opening file %w", err)
-		return
+		return err
+	}
+
+	if c.options.Recalc {
+		calculator, ok := c.sim.(Calculator)
+		if !ok {
+			c.sim.Close()
+			return fmt.Errorf("sim %s can't recalculate formulas", c.simPath)
+		}
+		c.sim = NewRecalcSim(calculator)
+	} else if workbook, ok := c.sim.(*excelize.File); ok {
+		// Other backends are loaded in memory already
+		c.sim = NewCachedSim(workbook)
+	}
+
+	return nil
+}
+
+// printRecalcProblems reports formulas which were not recalculated with -recalc
+func (c *GameLogCmd) printRecalcProblems() {
+	if recalc, ok := c.sim.(*RecalcSim); ok {
+		recalc.PrintProblems(os.Stderr)
+	}
+}
+
+// OpenSim opens a sim workbook, the backend is chosen by the file extension.
+// A directory or a zip archive is a sim exported to CSV files.
+func OpenSim(path string) (Sim, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return OpenCSV(path)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ods":
+		return OpenODS(path)
+	case ".zip":
+		return OpenCSV(path)
+	}
+
+	sim, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, WrapError(err, "error on opening sim file")
 	}
+
+	return sim, nil
+}
+
+// warnUnsupportedVersion prints a warning when the sim doesn't match the layout
+func (c *GameLogCmd) warnUnsupportedVersion() error {
+	check, err := CheckVersion(c.sim, []*Layout{c.layout})
+	if err != nil {
+		return err
+	}
+
+	if match, ok := check.Best(); !ok {
+		fmt.Fprintf(os.Stderr, "Warning: sim %s (%s) doesn't match layout %q (%d of %d headers), run check-version for details\n",
+			c.simPath, check.Fingerprint, match.Revision, match.Matched, match.Total)
+	}
+
+	return nil
 }
 
 func (c *GameLogCmd) readValue(sheet, cell, errorMsg string) (string, error) {
@@ -233,37 +308,95 @@ func (c *GameLogCmd) readFloatValue(sheet, cell, errorMsg string) (float64, erro
 	return digit, nil
 }
 
-func (c *GameLogCmd) Execute() {
-	defer c.sim.Close()
-	var sb strings.Builder
+func (c *GameLogCmd) Execute() error {
+	defer c.Close()
 
-	if cmdVars.hour > 0 {
-		c.setCurrentHour(cmdVars.hour)
-		result, err := c.executeActions()
-		if err != nil {
-			fmt.Println(err)
-		}
-		if result != "" {
-			sb.WriteString(result)
+	result, err := c.Generate()
+	if result != nil {
+		for _, violation := range result.Violations {
+			fmt.Fprintln(os.Stderr, violation)
 		}
-	} else {
-		for hr := 1; hr <= LastHour; hr++ {
-			c.setCurrentHour(hr)
-			result, err := c.executeActions()
-			if err != nil {
-				fmt.Println(err)
-				break
-			}
-			if result == "" {
-				continue
-			}
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.HourErr != nil {
+		fmt.Println(result.HourErr)
+	}
+
+	c.printRecalcProblems()
 
-			sb.WriteString(result)
-			sb.WriteString("\n")
+	c.PrintResult(result.Log)
+
+	return nil
+}
+
+func (c *GameLogCmd) Close() error {
+	return c.sim.Close()
+}
+
+// GameLogResult is a generated log with problems found on the way
+type GameLogResult struct {
+	Log        string
+	Hours      []*HourLog
+	Violations []Violation
+	// HourErr stopped the generation, Log has the hours before it
+	HourErr error
+}
+
+// Generate validates the sim and renders the log of the hour range without printing anything.
+// When the sim has validation errors the result has only violations.
+func (c *GameLogCmd) Generate() (*GameLogResult, error) {
+	violations, err := c.validateSim()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GameLogResult{Violations: violations}
+
+	if hasErrors(violations) && !c.options.Force {
+		return result, fmt.Errorf("sim has %d validation problems, fix them or use -force to generate the log anyway", len(violations))
+	}
+
+	hours, hourErr := c.generateHours()
+	result.Hours = hours
+	result.HourErr = hourErr
+
+	result.Log, err = c.render(hours)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// generateHours reads the events of the hour range, on error it returns the hours read before it
+func (c *GameLogCmd) generateHours() ([]*HourLog, error) {
+	hours := []*HourLog{}
+
+	for hr := c.options.From; hr <= c.options.To; hr++ {
+		c.setCurrentHour(hr)
+		hourLog, err := c.executeActions()
+		if err != nil {
+			return hours, err
 		}
+
+		hours = append(hours, hourLog)
+	}
+
+	return hours, nil
+}
+
+func (c *GameLogCmd) render(hours []*HourLog) (string, error) {
+	switch c.options.Format {
+	case FormatJSON:
+		return RenderJSON(hours)
+	case FormatNDJSON:
+		return RenderNDJSON(hours)
 	}
 
-	c.PrintResult(sb.String())
+	return RenderText(hours), nil
 }
 
 func (c *GameLogCmd) PrintResult(result string) {
@@ -281,64 +414,49 @@ func (c *GameLogCmd) PrintResult(result string) {
 	fmt.Printf("Successfully wrote result to %s\n", c.resultPath)
 }
 
-func (c *GameLogCmd) executeActions() (string, error) {
-	var sb strings.Builder
+// executeActions reads the events of the current hour
+func (c *GameLogCmd) executeActions() (*HourLog, error) {
+	hourLog, err := c.tickAction()
+	if err != nil {
+		return nil, fmt.Errorf("error on executing actions: %v", err)
+	}
 
 	for _, actionFunc := range c.actions {
-		result, err := actionFunc()
+		events, err := actionFunc()
 		if err != nil {
-			return "", fmt.Errorf("error on executing actions: %v", err)
-		}
-		if result == "" {
-			continue
-		}
-
-		sb.WriteString(result)
-
-		if !strings.HasSuffix(result, "\n") {
-			sb.WriteString("\n")
+			return nil, fmt.Errorf("error on executing actions: %v", err)
 		}
-	}
-
-	stringResult := sb.String()
 
-	// Skip if we have only timeline without actions
-	if strings.HasSuffix(stringResult, "==\n") {
-		stringResult = ""
-		sb.Reset()
+		hourLog.Events = append(hourLog.Events, events...)
 	}
 
-	return stringResult, nil
+	return hourLog, nil
 }
 
-func (c *GameLogCmd) tickAction() (string, error) {
-	const dateCell = "B15"
-	localTimeCell := c.wrapHour("BY")
-	domTimeCell := c.wrapHour("BZ")
-
-	localTimeValue, err := c.readValue(Imps, localTimeCell, "error reading local time")
+func (c *GameLogCmd) tickAction() (*HourLog, error) {
+	localTimeValue, err := c.readField("local_time", "error reading local time")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	domTimeValue, err := c.readValue(Imps, domTimeCell, "error reading dom time")
+	domTimeValue, err := c.readField("dom_time", "error reading dom time")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	dateValue, err := c.readValue(Overview, dateCell, "error reading date")
+	dateValue, err := c.readField("date", "error reading date")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	localTime, err := time.Parse("15:04", localTimeValue)
 	if err != nil {
-		return "", fmt.Errorf("error parsing local time: %w", err)
+		return nil, fmt.Errorf("error parsing local time: %w", err)
 	}
 
 	domTime, err := time.Parse("15:04", domTimeValue)
 	if err != nil {
-		return "", fmt.Errorf("error parsing dom time: %w", err)
+		return nil, fmt.Errorf("error parsing dom time: %w", err)
 	}
 
 	date, err := time.Parse("1/2/2006", dateValue)
@@ -347,7 +465,7 @@ func (c *GameLogCmd) tickAction() (string, error) {
 		if err != nil {
 			date, err = time.Parse("2006/01/02", dateValue)
 			if err != nil {
-				return "", WrapError(err, "error parsing date: %w")
+				return nil, WrapError(err, "error parsing date: %w")
 			}
 		}
 	}
@@ -358,651 +476,337 @@ func (c *GameLogCmd) tickAction() (string, error) {
 	domTime = time.Date(date.Year(), date.Month(), date.Day(),
 		domTime.Hour(), domTime.Minute(), 0, 0, time.UTC)
 
-	localTimeLong := localTime.Format("3:04:05 PM")
-	localTimeShort := localTime.Format("1/2/2006")
-	domTimeLong := domTime.Format("3:04:05 PM")
-	domTimeShort := domTime.Format("1/2/2006")
-
-	var timeline strings.Builder
-	timeline.WriteString("====== Protection Hour: ")
-	timeline.WriteString(fmt.Sprintf("%d", c.currentHour+1))
-	timeline.WriteString(" ( Local Time: ")
-	timeline.WriteString(localTimeLong)
-	timeline.WriteString(" ")
-	timeline.WriteString(localTimeShort)
-	timeline.WriteString(" ) ( Domtime: ")
-	timeline.WriteString(domTimeLong)
-	timeline.WriteString(" ")
-	timeline.WriteString(domTimeShort)
-	timeline.WriteString(" ) ======\n")
-
-	return timeline.String(), nil
+	return &HourLog{
+		Hour:      c.currentHour + 1,
+		LocalTime: localTime,
+		DomTime:   domTime,
+	}, nil
 }
 
-func (c *GameLogCmd) draftRateAction() (string, error) {
-	currentRateCell := c.wrapHour("Y")
-	previousRateCell := c.wrapHourAs("Z", c.simHour-1)
+func (c *GameLogCmd) draftRateAction() ([]Event, error) {
+	previousRate := c.layout.Field("previous_draftrate")
+	previousRateCell := c.wrapHourAs(previousRate.Column, c.simHour-1)
 
-	currentRateStr, err := c.readValue(Military, currentRateCell, "error reading current draftrate")
+	currentRateStr, err := c.readField("draftrate", "error reading current draftrate")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	previousRateStr, err := c.readValue(Military, previousRateCell, "error reading previous draftrate")
+	previousRateStr, err := c.readValue(previousRate.Sheet, previousRateCell, "error reading previous draftrate")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var buf strings.Builder
-
 	if currentRateStr == "" || currentRateStr == previousRateStr {
-		return "", nil
+		return nil, nil
 	}
 
-	buf.WriteString("Draftrate changed to ")
-	buf.WriteString(currentRateStr)
-	buf.WriteString(".\n")
-
-	return buf.String(), nil
+	return []Event{DraftRateChanged{Rate: currentRateStr}}, nil
 }
 
-func (c *GameLogCmd) releaseUnitsAction() (string, error) {
-	// Read unit names and unit counts
-	cols := []string{"AX", "AY", "AZ", "BA", "BB", "BC", "BD", "BE"}
+// readAmounts reads not empty values of group columns, names are taken from
+// the header row when nameFromHeader is set
+func (c *GameLogCmd) readAmounts(group LayoutGroup, nameFromHeader bool, errorMsg string) ([]Amount, error) {
+	amounts := []Amount{}
 
-	var sb strings.Builder
-	sb.WriteString("You successfully released ")
-
-	addedItems := 0
-	for _, col := range cols {
-		name, err := c.readValue(Military, c.wrapHourAs(col, 2), "error reading unit name")
-		if err != nil {
-			return "", err
-		}
-
-		value, err := c.readIntValue(Military, c.wrapHour(col), "error reading unit value")
+	for _, col := range group.Columns {
+		value, err := c.readIntValue(group.Sheet, c.wrapHour(col.Column), errorMsg)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		if value == 0 {
 			continue
 		}
 
-		if addedItems > 0 {
-			sb.WriteString(", ")
+		name := col.Name
+		if nameFromHeader {
+			name, err = c.readValue(group.Sheet, c.wrapHourAs(col.Column, group.HeaderRow), "error reading unit name")
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		sb.WriteString(fmt.Sprintf("%d %s", value, name))
-		addedItems++
+		amounts = append(amounts, Amount{Name: name, Value: value})
 	}
 
-	if addedItems == 0 {
-		sb.Reset()
-	} else {
-		sb.WriteString(".\n")
+	return amounts, nil
+}
+
+func (c *GameLogCmd) releaseUnitsAction() ([]Event, error) {
+	units, err := c.readAmounts(c.layout.Group("release"), true, "error reading unit value")
+	if err != nil {
+		return nil, err
 	}
 
-	// Read draftees count from AW column
-	drafteesCell := c.wrapHour("AW")
-	draftees, err := c.readIntValue(Military, drafteesCell, "error reading draftees value")
+	draftees, err := c.readIntField("release_draftees", "error reading draftees value")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if draftees > 0 {
-		sb.WriteString(fmt.Sprintf("You successfully released %d draftees into the peasantry.\n", draftees))
+	if len(units) == 0 && draftees <= 0 {
+		return nil, nil
 	}
 
-	return sb.String(), nil
+	return []Event{UnitsReleased{Units: units, Draftees: draftees}}, nil
 }
 
-func (c *GameLogCmd) castMagicSpells() (string, error) {
-	var sb strings.Builder
-
-	landBonusVal, err := c.readIntValue(Explore, c.wrapHour("S"), "error on reading explore cell")
+func (c *GameLogCmd) castMagicSpells() ([]Event, error) {
+	casts, err := c.spellCasts(c.currentHour + 1)
 	if err != nil {
-		return "", err
+		return nil, WrapError(err, "error on casting magic spell")
 	}
 
-	landSize, err := c.readLandSize()
-	if err != nil {
-		return "", err
+	var events []Event
+	for _, cast := range casts {
+		events = append(events, SpellCast{Spell: cast.Spell, Mana: cast.Cost})
 	}
 
-	checkAndCastSpell := func(spellName, magicCol, multCell string, isRacial bool) error {
-		if isRacial {
-			spellName = RacialSpell
-		}
-		magicCell := c.wrapHour(magicCol)
-		magicVal, err := c.readIntValue(Magic, magicCell, "error on reading magic cell")
-		if err != nil {
-			return err
-		}
-
-		multVal, err := c.readConst(multCell)
-		if err != nil {
-			multVal = 2
-			// return err
-		}
-
-		mana := 0
-		// if land bonus received
-		if landBonusVal != 0 && magicVal != 0 {
-			mana = FloatToInt((float64(landSize) - LandBonus) * multVal)
-		} else if magicVal != 0 {
-			mana = FloatToInt(float64(landSize) * multVal)
-		} else {
-			return nil // No spell was cast, so no message to add
-		}
-
-		sb.WriteString(fmt.Sprintf("Your wizards successfully cast %s at a cost of %d mana.\n", spellName, mana))
-
-		return nil
-	}
-
-	spells := []struct {
-		name     string
-		cell     string
-		mult     string
-		isRacial bool
-	}{
-		{GaiasWatch, "G", "B75", false},
-		{MiningStrength, "H", "B76", false},
-		{AresCall, "I", "B77", false},
-		{MidasTouch, "J", "B78", false},
-		{Harmony, "K", "B79", false},
-		{"", "L", "B80", true},
-		{"", "M", "B80", true},
-		{"", "N", "B80", true},
-		{"", "O", "B80", true},
-		{"", "P", "B80", true},
-		{"", "Q", "B80", true},
-		{"", "R", "B80", true},
-		{"", "S", "B80", true},
-		{"", "T", "B80", true},
-		{"", "U", "B80", true},
-	}
-
-	// Check and cast each spell
-	for _, spell := range spells {
-		if err := checkAndCastSpell(spell.name, spell.cell, spell.mult, spell.isRacial); err != nil {
-			return "", WrapError(err, "error on casting magic spell")
-		}
-	}
-	return sb.String(), nil
+	return events, nil
 }
 
-func (c *GameLogCmd) unlockTechAction() (string, error) {
+func (c *GameLogCmd) unlockTechAction() ([]Event, error) {
 	// Check if a tech was unlocked
-	techUnlocked, err := c.readIntValue(Techs, c.wrapHour("K"), "error reading tech status")
+	techUnlocked, err := c.readIntField("tech_unlocked", "error reading tech status")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if techUnlocked > 0 {
-		techName, err := c.readValue(Techs, c.wrapHour("CA"), "error reading tech name")
+		techName, err := c.readField("tech_name", "error reading tech name")
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		return fmt.Sprintf("You have unlocked %s.\n", techName), nil
+		return []Event{TechUnlocked{Tech: techName}}, nil
 	}
 
-	return "", nil
+	return nil, nil
 }
 
-func (c *GameLogCmd) dailtyPlatinumAction() (string, error) {
-	platChecked, err := c.readIntValue(Production, c.wrapHour("C"), "error reading platinum bonus")
+func (c *GameLogCmd) dailtyPlatinumAction() ([]Event, error) {
+	platChecked, err := c.readIntField("daily_platinum", "error reading platinum bonus")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if platChecked == 0 {
-		return "", nil
+		return nil, nil
 	}
 
-	populationValue, err := c.readIntValue(Population, c.wrapHour("C"), "error reading population")
+	populationValue, err := c.readIntField("peasants", "error reading population")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	platinumAwarded := populationValue * PlatAwardedMult
-	return fmt.Sprintf("You have been awarded with %d platinum.\n", platinumAwarded), nil
+	return []Event{DailyPlatinum{Platinum: platinumAwarded}}, nil
 }
 
-func (c *GameLogCmd) tradeResources() (string, error) {
-	var sb strings.Builder
-
-	plat, err := c.readIntValue(Production, c.wrapHour("BC"), "can't read platinum value for trading")
+func (c *GameLogCmd) tradeResources() ([]Event, error) {
+	plat, err := c.readIntField("trade_platinum", "can't read platinum value for trading")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	lumber, err := c.readIntValue(Production, c.wrapHour("BD"), "can't read lumber value for trading")
+	lumber, err := c.readIntField("trade_lumber", "can't read lumber value for trading")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	ore, err := c.readIntValue(Production, c.wrapHour("BE"), "can't read ore value for trading")
+	ore, err := c.readIntField("trade_ore", "can't read ore value for trading")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	gems, err := c.readIntValue(Production, c.wrapHour("BF"), "can't read gems value for trading")
+	gems, err := c.readIntField("trade_gems", "can't read gems value for trading")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if plat == 0 && lumber == 0 && ore == 0 && gems == 0 { // Check if any exchange happened
-		return "", nil
+		return nil, nil
 	}
-	var tradedItems []string
-	var receivedItems []string
 
-	addItem := func(item string, amount int) {
-		formatValue := func(value int) string {
-			return fmt.Sprintf("%d %s", value, item)
-		}
-
-		if amount < 0 {
-			tradedItems = append(tradedItems, formatValue(-amount))
-		} else if amount > 0 {
-			receivedItems = append(receivedItems, formatValue(amount))
-		}
-	}
-
-	addItem("platinum", plat)
-	addItem("lumber", lumber)
-	addItem("ore", ore)
-	addItem("gems", gems)
-
-	// Construct the action message
-	if len(tradedItems) > 0 {
-		sb.WriteString(strings.Join(tradedItems, " and ") + " have been traded for ")
-	}
-	if len(receivedItems) > 0 {
-		sb.WriteString(strings.Join(receivedItems, " and ") + ".\n")
-	}
-
-	return sb.String(), nil
+	return []Event{ResourcesTraded{Resources: []Amount{
+		{Name: "platinum", Value: plat},
+		{Name: "lumber", Value: lumber},
+		{Name: "ore", Value: ore},
+		{Name: "gems", Value: gems},
+	}}}, nil
 }
 
-func (c *GameLogCmd) exploreAction() (string, error) {
-	var sb strings.Builder
-
-	sb.WriteString("Exploration for ")
-
-	addedItems := 0
-	// Read exploration counts for each land type
-	for landType, col := range exploreLands {
-		cell := c.wrapHour(col)
-		value, err := c.readIntValue(Explore, cell, "error on reading land amount")
-		if err != nil {
-			return "", err
-		}
-
-		if value == 0 {
-			continue
-		}
-
-		if addedItems > 0 {
-			sb.WriteString(", ")
-		}
-
-		sb.WriteString(fmt.Sprintf("%d %s", value, landType))
-		addedItems++
+func (c *GameLogCmd) exploreAction() ([]Event, error) {
+	lands, err := c.readAmounts(c.layout.Group("explore"), false, "error on reading land amount")
+	if err != nil {
+		return nil, err
 	}
 
-	if addedItems == 0 {
-		sb.Reset()
-		return "", nil
+	if len(lands) == 0 {
+		return nil, nil
 	}
 
 	// Read cost values
-	platCost, err := c.readIntValue(Explore, c.wrapHour("AH"), "error reading explore plat cost")
+	platCost, err := c.readIntField("explore_platinum_cost", "error reading explore plat cost")
 	if err != nil {
-		return "", nil
+		return nil, err
 	}
-	drafteeCost, err := c.readIntValue(Explore, c.wrapHour("AI"), "error reading explore draftees costs")
+	drafteeCost, err := c.readIntField("explore_draftee_cost", "error reading explore draftees costs")
 	if err != nil {
-		return "", nil
+		return nil, err
 	}
 
-	sb.WriteString(fmt.Sprintf(" begun at a cost of %d platinum and %d draftees.\n", platCost, drafteeCost))
-
-	return sb.String(), nil
+	return []Event{ExplorationStarted{Lands: lands, PlatinumCost: platCost, DrafteeCost: drafteeCost}}, nil
 }
 
-func (c *GameLogCmd) dailyLandAction() (string, error) {
-	landBonus, err := c.readIntValue(Explore, c.wrapHour("S"), "error on reading land bonus value")
+func (c *GameLogCmd) dailyLandAction() ([]Event, error) {
+	landBonus, err := c.readIntField("land_bonus", "error on reading land bonus value")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if landBonus == 0 {
-		return "", nil
+		return nil, nil
 	}
 
-	landType, err := c.readValue(Overview, "B70", "error reading land type")
+	landType, err := c.readField("home_land", "error reading land type")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("You have been awarded with %d %s.\n", LandBonus, landType), nil
+	return []Event{DailyLand{Acres: LandBonus, Land: landType}}, nil
 }
 
-func (c *GameLogCmd) destroyBuildingsAction() (string, error) {
-	var sb strings.Builder
-
-	cols := []string{
-		"BW", "BX", "BY", "BZ", "CA", "CB", "CD", "CE", "CF", "CG",
-		"CH", "CI", "CJ", "CK", "CL", "CM", "CN", "CO",
-	}
-
-	sb.WriteString("Destruction of ")
-	addedItems := 0
-
-	for index, col := range cols {
-		name := buildingNames[index]
-		value, err := c.readIntValue(Construction, c.wrapHour(col), "error on reading destroy value")
-		if err != nil {
-			return "", err
-		}
-
-		if value == 0 {
-			continue
-		}
-
-		if addedItems > 0 {
-			sb.WriteString(", ")
-		}
-
-		sb.WriteString(fmt.Sprintf("%d %s", value, name))
-		addedItems++
+func (c *GameLogCmd) destroyBuildingsAction() ([]Event, error) {
+	buildings, err := c.readAmounts(c.layout.Group("destruction"), false, "error on reading destroy value")
+	if err != nil {
+		return nil, err
 	}
 
-	if addedItems == 0 {
-		sb.Reset()
-		return "", nil
+	if len(buildings) == 0 {
+		return nil, nil
 	}
 
-	sb.WriteString(" is complete.\n")
-
-	return sb.String(), nil
+	return []Event{BuildingsDestroyed{Buildings: buildings}}, nil
 }
 
-func (c *GameLogCmd) rezoneAction() (string, error) {
-	var sb strings.Builder
-
-	platCost, err := c.readIntValue(Rezone, c.wrapHour("Y"), "error on reading rezone cost")
+func (c *GameLogCmd) rezoneAction() ([]Event, error) {
+	platCost, err := c.readIntField("rezone_platinum_cost", "error on reading rezone cost")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if platCost == 0 {
-		return "", nil
+		return nil, nil
 	}
 
-	sb.WriteString(fmt.Sprintf("Rezoning begun at a cost of %d platinum. The changes in land are as following: ", platCost))
-
-	addedItems := 0
-	for landType, col := range rezoneLands {
-		value, err := c.readIntValue(Rezone, c.wrapHour(col), "error on reading rezone value")
-		if err != nil {
-			return "", err
-		}
-
-		if value == 0 {
-			continue
-		}
-		if addedItems > 0 {
-			sb.WriteString(", ")
-		}
-
-		sb.WriteString(fmt.Sprintf("%d %s", value, landType))
-		addedItems++
+	lands, err := c.readAmounts(c.layout.Group("rezone"), false, "error on reading rezone value")
+	if err != nil {
+		return nil, err
 	}
 
-	sb.WriteString(".\n")
-
-	return sb.String(), nil
+	return []Event{Rezoned{Lands: lands, PlatinumCost: platCost}}, nil
 }
 
-func (c *GameLogCmd) constructionAction() (string, error) {
-	var sb strings.Builder
-	sb.WriteString("Construction of ")
-
-	cols := []string{"O", "P", "Q", "R", "S", "T", "V", "W", "X", "Y", "Z", "AA", "AB", "AC", "AD", "AE", "AF", "AG"}
-
-	addedItems := 0
-	for index, col := range cols {
-		name := buildingNames[index]
-		value, err := c.readIntValue(Construction, c.wrapHour(col), "error on reading construction value")
-		if err != nil {
-			return "", err
-		}
-		if value == 0 {
-			continue
-		}
-		if addedItems > 0 {
-			sb.WriteString(", ")
-		}
-
-		sb.WriteString(fmt.Sprintf("%d %s", value, name))
-		addedItems++
+func (c *GameLogCmd) constructionAction() ([]Event, error) {
+	buildings, err := c.readAmounts(c.layout.Group("construction"), false, "error on reading construction value")
+	if err != nil {
+		return nil, err
 	}
 
-	if addedItems == 0 {
-		sb.Reset()
-		return "", nil
+	if len(buildings) == 0 {
+		return nil, nil
 	}
 
 	// Read cost values
-	platCost, err := c.readIntValue(Construction, c.wrapHour("AQ"), "error reading platinum cost")
+	platCost, err := c.readIntField("construction_platinum_cost", "error reading platinum cost")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	lumberCost, err := c.readIntValue(Construction, c.wrapHour("AR"), "error reading lumber cost")
+	lumberCost, err := c.readIntField("construction_lumber_cost", "error reading lumber cost")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	sb.WriteString(fmt.Sprintf(" started at a cost of %d platinum and %d lumber.\n", platCost, lumberCost))
-
-	return sb.String(), nil
+	return []Event{ConstructionStarted{Buildings: buildings, PlatinumCost: platCost, LumberCost: lumberCost}}, nil
 }
 
-func (c *GameLogCmd) trainUnitsAction() (string, error) {
-	var sb strings.Builder
-	sb.WriteString("Training of ")
-	cols := []string{"AG", "AH", "AI", "AJ", "AK", "AL", "AM", "AN"}
-	addedItems := 0
+func (c *GameLogCmd) trainUnitsAction() ([]Event, error) {
+	group := c.layout.Group("train")
+	event := UnitsTrained{}
 
-	drafteesCount := 0
-	spiesCount := 0
-	wizardCount := 0
-	for index, col := range cols {
-		name, err := c.readValue(Military, c.wrapHourAs(col, 2), "error reading unit name cell")
+	for _, col := range group.Columns {
+		name, err := c.readValue(group.Sheet, c.wrapHourAs(col.Column, group.HeaderRow), "error reading unit name cell")
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		value, err := c.readIntValue(Military, c.wrapHour(col), "error reading unit value cell")
+		value, err := c.readIntValue(group.Sheet, c.wrapHour(col.Column), "error reading unit value cell")
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		if value == 0 {
 			continue
 		}
 
-		switch index {
-		case 5:
-			spiesCount += value
-		case 7:
-			wizardCount += value
+		// Archspies are trained from spies and archmages from wizards
+		switch col.Source {
+		case "spies":
+			event.SpyCost += value
+		case "wizards":
+			event.WizardCost += value
 		default:
-			drafteesCount += value
+			event.DrafteeCost += value
 		}
 
-		if addedItems > 0 {
-			sb.WriteString(", ")
-		}
-
-		sb.WriteString(fmt.Sprintf("%d %s", value, name))
-		addedItems++
+		event.Units = append(event.Units, Amount{Name: name, Value: value})
 	}
 
-	if addedItems == 0 {
-		return "", nil
+	if len(event.Units) == 0 {
+		return nil, nil
 	}
 
-	platCost, err := c.readIntValue(Military, c.wrapHour("AR"), "error reading platinum training cost")
+	platCost, err := c.readIntField("train_platinum_cost", "error reading platinum training cost")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	oreCost, err := c.readIntValue(Military, c.wrapHour("AS"), "error reading ore training cost")
+	oreCost, err := c.readIntField("train_ore_cost", "error reading ore training cost")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	sb.WriteString(fmt.Sprintf(" begun at a cost of %d platinum, %d ore, %d draftees, %d spies, and %d wizards.\n",
-		platCost, oreCost, drafteesCount, spiesCount, wizardCount))
+	event.PlatinumCost = platCost
+	event.OreCost = oreCost
 
-	return sb.String(), nil
+	return []Event{event}, nil
 }
 
-func (c *GameLogCmd) improvementsAction() (string, error) {
-	var sb strings.Builder
+func (c *GameLogCmd) improvementsAction() ([]Event, error) {
+	var events []Event
+
+	group := c.layout.Group("improvements")
 
-	checkAndFormatImprovement := func(amountCol, resourceCol, targetCol string) (string, error) {
-		amount, err := c.readIntValue(Imps, c.wrapHour(amountCol), "error on read amout cell")
+	for _, imp := range group.Columns {
+		amount, err := c.readIntValue(group.Sheet, c.wrapHour(imp.Column), "error on read amout cell")
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if amount == 0 {
-			return "", nil
+			continue
 		}
 
-		resource, err := c.readValue(Imps, c.wrapHour(resourceCol), "error on read resource cell")
+		resource, err := c.readValue(group.Sheet, c.wrapHour(imp.Resource), "error on read resource cell")
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		target, err := c.readValue(Imps, c.wrapHour(targetCol), "error on read improvement cell")
+		target, err := c.readValue(group.Sheet, c.wrapHour(imp.Target), "error on read improvement cell")
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		return fmt.Sprintf("You invested %d %s into %s.\n", amount, resource, target), nil
+		events = append(events, Invested{Amount: amount, Resource: resource, Improvement: target})
 	}
 
-	improvments := []struct {
-		amountCell   string
-		resourceCell string
-		targetCell   string
-	}{
-		{"P", "O", "Q"},
-		{"S", "R", "T"},
-		{"V", "U", "W"},
-	}
-
-	for _, imp := range improvments {
-		result, err := checkAndFormatImprovement(imp.amountCell, imp.resourceCell, imp.targetCell)
-		if err != nil {
-			return "", err
-		}
-
-		sb.WriteString(result)
-	}
-
-	return sb.String(), nil
-}
-
-//
-// // ... (your other types, constants, ExcelizeInterface, etc.)
-//
-// func (c *GameLogCmd) stats(outputSheet, outputTextBox string) error {
-// 	var stats strings.Builder
-//
-// 	// Get log hour, defaulting to 72
-// 	logHourStr, err := c.sim.GetCellValue("Overview", "I28")
-// 	if err != nil {
-// 		return fmt.Errorf("error reading log hour: %w", err)
-// 	}
-// 	logHour := 72 // Default value
-// 	if logHourStr != "" {
-// 		logHour, _ = strconv.Atoi(logHourStr)
-// 	}
-// 	hr := logHour + 4 // The hour to read statistics from
-//
-// 	// Helper function to get and format cell values
-// 	getFormattedValue := func(sheet, axis string, format string) (string, error) {
-// 		val, err := c.sim.GetCellValue(sheet, axis)
-// 		if err != nil {
-// 			return "", fmt.Errorf("error reading cell %s!%s: %w", sheet, axis, err)
-// 		}
-// 		if format == "#,##0" || format == "#,###" {
-// 			intVal, err := strconv.Atoi(val)
-// 			if err != nil {
-// 				return "", fmt.Errorf("error converting cell %s!%s value to int: %w", sheet, axis, err)
-// 			}
-// 			// Format the integer with commas
-// 			return fmt.Sprintf(format, intVal), nil
-// 		}
-// 		return fmt.Sprintf(format, val), nil
-// 	}
-//
-// 	// Function to append a stat line to the builder
-// 	addStatLine := func(label, sheet, axis, format string) error {
-// 		val, err := getFormattedValue(sheet, fmt.Sprintf(axis, hr), format)
-// 		if err != nil {
-// 			return err
-// 		}
-// 		stats.WriteString(fmt.Sprintf("%s:  %s\n", label, val))
-// 		return nil
-// 	}
-// 	// 1. Basic Overview
-// 	stats.WriteString(fmt.Sprintf("The Dominion of Simulated Dominion: Hour %d\nOverview\n", logHour))
-// 	addStatLine("Ruler:", "Overview", "B14", "%s")
-// 	addStatLine("Race:", "Overview", "B14", "%s")
-// 	addStatLine("Land:", "Production", "E%d", "#,###")
-// 	addStatLine("Peasants:", "Population", "C%d", "#,###")
-// 	addStatLine("Draftees:", "Population", "E%d", "#,###")
-// 	addStatLine("Employment:", "Population", "I%d", "%.2f%%") // Multiply by 100 for percentage and format to 2 decimal places
-// 	addStatLine("Networth:", "Production", "G%d", "#,###")
-//
-// 	// 2. Resources
-// 	stats.WriteString("\nResources\n")
-// 	addStatLine("Platinum:", "Production", "H%d", "#,###")
-// 	addStatLine("Food:", "Production", "I%d", "#,##0")
-// 	addStatLine("Lumber:", "Production", "J%d", "#,##0")
-// 	addStatLine("Mana:", "Production", "K%d", "#,##0")
-// 	addStatLine("Ore:", "Production", "L%d", "#,##0")
-// 	addStatLine("Gems:", "Production", "M%d", "#,##0")
-// 	addStatLine("Boats:", "Production", "N%d", "#,##0")
-//
-// 	// 3. Military
-// 	stats.WriteString("\nMilitary\n")
-// 	stats.WriteString("Morale:  100.00%\n")
-// 	for row := 36; row <= 39; row++ {
-// 		unitLabel, _ := c.sim.GetCellValue("Overview", fmt.Sprintf("A%d", row))
-// 		unitCount, _ := getFormattedValue("Military", fmt.Sprintf("%c%d", 'E'+row-36, hr), "#,##0")
-// 		stats.WriteString(fmt.Sprintf("%s:  %s\n", unitLabel, unitCount))
-// 	}
-// 	addStatLine("Spies:", "Military", "I%d", "#,##0")
-// 	addStatLine("Archspies:", "Military", "J%d", "#,##0")
-// 	addStatLine("Wizards:", "Military", "K%d", "#,##0")
-// 	addStatLine("Archmages:", "Military", "L%d", "#,##0")
-//
-// 	// 4. Additional Stats from Table
-// 	stats.WriteString("\n--------------------------------------------------\n")
-//
-// 	// ... logic to read and format additional stats from the "Log_support" sheet
-//
-// 	if _, err := c.sim.SetCellValue(outputSheet, outputTextBox, stats.String()); err != nil {
-// 		return fmt.Errorf("error writing stats to Excel: %w", err)
-// 	}
-//
-// 	return nil
-// }
+	return events, nil
+}
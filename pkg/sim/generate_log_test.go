@@ -9,7 +9,13 @@ import (
 )
 
 type SimMock struct {
-	Data map[string]map[string]string // In-memory representation of Excel data
+	Data         map[string]map[string]string // In-memory representation of Excel data
+	AllowMissing bool                         // Missing cells are empty like in excelize
+	Sheets       []string
+}
+
+func (s *SimMock) GetSheetList() []string {
+	return s.Sheets
 }
 
 func (s *SimMock) GetCellValue(sheet, cell string, _ ...excelize.Options) (string, error) {
@@ -18,6 +24,9 @@ func (s *SimMock) GetCellValue(sheet, cell string, _ ...excelize.Options) (strin
 			return cellValue, nil
 		}
 	}
+	if s.AllowMissing {
+		return "", nil
+	}
 	return "", fmt.Errorf("Cell %s!%s is missing", sheet, cell)
 }
 
@@ -28,11 +37,20 @@ func (s *SimMock) Close() error {
 func newMockGameLog(sim *SimMock, actions ...ActionFunc) *GameLogCmd {
 	return &GameLogCmd{
 		currentHour: 0,
+		layout:      mustDefaultLayout(),
 		sim:         sim,
 		actions:     actions,
 	}
 }
 
+func mustDefaultLayout() *Layout {
+	layout, err := DefaultLayout()
+	if err != nil {
+		panic(err)
+	}
+	return layout
+}
+
 // deepCopyAndMergeMaps creates a deep copy of the source map
 // and merges the data from the override map into it.
 func deepCopyAndMergeMaps(src map[string]map[string]string, override map[string]map[string]string) map[string]map[string]string {
@@ -69,7 +87,7 @@ func TestTickAction(t *testing.T) {
 				Overview: {"B15": "5/18/2024"},
 				Imps:     {"BY4": "18:00", "BZ4": "00:00"},
 			},
-			expected:    "====== Protection Hour: 1 ( Local Time: 6:00:00 PM 5/18/2024 ) ( Domtime: 12:00:00 AM 5/18/2024 ) ======",
+			expected:    "====== Protection Hour: 1 ( Local Time: 6:00:00 PM 5/18/2024 ) ( Domtime: 12:00:00 AM 5/18/2024 ) ======\n",
 			expectedErr: nil,
 		},
 		{
@@ -108,10 +126,15 @@ func TestTickAction(t *testing.T) {
 			glc := &GameLogCmd{
 				currentHour: 0,
 				simHour:     4,
+				layout:      mustDefaultLayout(),
 				sim:         mockSim,
 			}
 
-			result, err := glc.tickAction()
+			hourLog, err := glc.tickAction()
+			result := ""
+			if hourLog != nil {
+				result = renderTimeline(hourLog)
+			}
 
 			if err != nil && tc.expectedErr == nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -143,7 +166,7 @@ func TestDraftRateAction(t *testing.T) {
 					"Z4": "80%",
 				},
 			},
-			expected:    "Draftrate changed to 90%",
+			expected:    "Draftrate changed to 90%.\n",
 			expectedErr: nil,
 			currentHour: 1,
 		},
@@ -155,7 +178,7 @@ func TestDraftRateAction(t *testing.T) {
 					"Z4": "",
 				},
 			},
-			expected:    "Draftrate changed to 90%",
+			expected:    "Draftrate changed to 90%.\n",
 			expectedErr: nil,
 			currentHour: 1,
 		},
@@ -209,10 +232,12 @@ func TestDraftRateAction(t *testing.T) {
 			glc := &GameLogCmd{
 				currentHour: tc.currentHour,
 				simHour:     tc.currentHour + 4,
+				layout:      mustDefaultLayout(),
 				sim:         mockSim,
 			}
 
-			result, err := glc.draftRateAction()
+			events, err := glc.draftRateAction()
+			result := renderEvents(events)
 			if err != nil && tc.expectedErr == nil {
 				t.Errorf("Unexpected error: %v", err)
 			} else if err == nil && tc.expectedErr != nil {
@@ -266,7 +291,7 @@ func TestReleaseUnitsAction(t *testing.T) {
 					"AW4": "20",
 				},
 			},
-			expected:    "You successfully released 10 Spearman, 5 Archer\nYou successfully released 20 draftees into the peasantry\n",
+			expected:    "You successfully released 10 Spearman, 5 Archer.\nYou successfully released 20 draftees into the peasantry.\n",
 			expectedErr: nil,
 			currentHour: 0,
 		},
@@ -280,7 +305,7 @@ func TestReleaseUnitsAction(t *testing.T) {
 					"BE4": "10",
 				},
 			},
-			expected:    "You successfully released 10 Spies, 5 Archspies, 20 Wizards, 10 Archmages\n",
+			expected:    "You successfully released 10 Spies, 5 Archspies, 20 Wizards, 10 Archmages.\n",
 			expectedErr: nil,
 			currentHour: 0,
 		},
@@ -292,7 +317,7 @@ func TestReleaseUnitsAction(t *testing.T) {
 					"AZ4": "3",
 				},
 			},
-			expected:    "You successfully released 3 Knight\n",
+			expected:    "You successfully released 3 Knight.\n",
 			expectedErr: nil,
 			currentHour: 0,
 		},
@@ -303,7 +328,7 @@ func TestReleaseUnitsAction(t *testing.T) {
 					"AW4": "15",
 				},
 			},
-			expected:    "You successfully released 15 draftees into the peasantry\n",
+			expected:    "You successfully released 15 draftees into the peasantry.\n",
 			expectedErr: nil,
 			currentHour: 0,
 		},
@@ -354,10 +379,12 @@ func TestReleaseUnitsAction(t *testing.T) {
 			glc := &GameLogCmd{
 				currentHour: tc.currentHour,
 				simHour:     tc.currentHour + 4,
+				layout:      mustDefaultLayout(),
 				sim:         mockSim,
 			}
 
-			result, err := glc.releaseUnitsAction()
+			events, err := glc.releaseUnitsAction()
+			result := renderEvents(events)
 			if err != nil && tc.expectedErr == nil {
 				t.Errorf("Unexpected error: %v", err)
 			} else if err == nil && tc.expectedErr != nil {
@@ -371,3 +398,101 @@ func TestReleaseUnitsAction(t *testing.T) {
 		})
 	}
 }
+
+func TestExploreAction(t *testing.T) {
+	testCases := []struct {
+		name        string
+		explore     map[string]string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "Exploration",
+			explore:  map[string]string{"T4": "10", "AH4": "4,000", "AI4": "20"},
+			expected: "Exploration for 10 Plains begun at a cost of 4000 platinum and 20 draftees.\n",
+		},
+		{
+			name:        "Error Reading Platinum Cost",
+			explore:     map[string]string{"T4": "10", "AH4": "#REF!", "AI4": "20"},
+			expectedErr: "error reading explore plat cost",
+		},
+		{
+			name:        "Error Reading Draftee Cost",
+			explore:     map[string]string{"T4": "10", "AH4": "4000", "AI4": "#VALUE!"},
+			expectedErr: "error reading explore draftees costs",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			glc := newMockGameLog(&SimMock{Data: map[string]map[string]string{Explore: tc.explore}, AllowMissing: true})
+			glc.setCurrentHour(1)
+
+			events, err := glc.exploreAction()
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("Expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result := renderEvents(events); result != tc.expected {
+				t.Errorf("Incorrect result: got %q, want %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateHours(t *testing.T) {
+	simData := map[string]map[string]string{
+		Overview: {"B15": "5/18/2024"},
+		Imps:     {},
+	}
+	for hr := 1; hr <= LastHour; hr++ {
+		simData[Imps][fmt.Sprintf("BY%d", hr+3)] = "18:00"
+		simData[Imps][fmt.Sprintf("BZ%d", hr+3)] = "00:00"
+	}
+
+	testCases := []struct {
+		name      string
+		from      int
+		to        int
+		failHour  int
+		expected  []int
+		expectErr bool
+	}{
+		{name: "Range", from: 2, to: 4, expected: []int{2, 3, 4}},
+		{name: "Single hour", from: 5, to: 5, expected: []int{5}},
+		{name: "Continue to the last hour", from: 72, to: LastHour, expected: []int{72, 73}},
+		{name: "Stops on error", from: 1, to: 5, failHour: 3, expected: []int{1, 2}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			glc := newMockGameLog(&SimMock{Data: simData})
+			glc.actions = []ActionFunc{func() ([]Event, error) {
+				if glc.currentHour+1 == tc.failHour {
+					return nil, fmt.Errorf("broken hour")
+				}
+				return nil, nil
+			}}
+			glc.options = GameLogOptions{From: tc.from, To: tc.to}
+
+			hours, err := glc.generateHours()
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			result := []int{}
+			for _, hourLog := range hours {
+				result = append(result, hourLog.Hour)
+			}
+			if fmt.Sprint(result) != fmt.Sprint(tc.expected) {
+				t.Errorf("Incorrect hours: got %v, want %v", result, tc.expected)
+			}
+		})
+	}
+}
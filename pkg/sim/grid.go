@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"github.com/xuri/excelize/v2"
+)
+
+// gridSim is a sim loaded in memory as rows of cell values. It backs the sim
+// formats read without excelize and follows excelize semantics: cells outside
+// the data are empty and rows have no trailing empty cells.
+type gridSim struct {
+	sheets []string
+	rows   map[string][][]string
+}
+
+func newGridSim() *gridSim {
+	return &gridSim{rows: map[string][][]string{}}
+}
+
+func (g *gridSim) addSheet(name string) {
+	if _, ok := g.rows[name]; ok {
+		return
+	}
+
+	g.sheets = append(g.sheets, name)
+	g.rows[name] = [][]string{}
+}
+
+// setCell stores a value by 1-based row and column, empty values are skipped
+func (g *gridSim) setCell(sheet string, row, col int, value string) {
+	if value == "" {
+		return
+	}
+
+	rows := g.rows[sheet]
+	for len(rows) < row {
+		rows = append(rows, nil)
+	}
+	for len(rows[row-1]) < col {
+		rows[row-1] = append(rows[row-1], "")
+	}
+	rows[row-1][col-1] = value
+	g.rows[sheet] = rows
+}
+
+func (g *gridSim) GetCellValue(sheet, cell string, _ ...excelize.Options) (string, error) {
+	rows, ok := g.rows[sheet]
+	if !ok {
+		return "", excelize.ErrSheetNotExist{SheetName: sheet}
+	}
+
+	col, row, err := excelize.CellNameToCoordinates(cell)
+	if err != nil {
+		return "", err
+	}
+
+	if row > len(rows) || col > len(rows[row-1]) {
+		return "", nil
+	}
+
+	return rows[row-1][col-1], nil
+}
+
+func (g *gridSim) GetRows(sheet string, _ ...excelize.Options) ([][]string, error) {
+	rows, ok := g.rows[sheet]
+	if !ok {
+		return nil, excelize.ErrSheetNotExist{SheetName: sheet}
+	}
+
+	return rows, nil
+}
+
+func (g *gridSim) GetSheetList() []string {
+	return g.sheets
+}
+
+func (g *gridSim) Close() error {
+	return nil
+}
@@ -3,6 +3,7 @@ package sim
 import (
 	"fmt"
 	"math"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -44,3 +45,28 @@ func WrapError(err error, msg string) error {
 func FloatToInt(value float64) int {
 	return int(math.Round(value))
 }
+
+// Output formats of the commands
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatJUnit  = "junit"
+	FormatYAML   = "yaml"
+	FormatCSV    = "csv"
+)
+
+// writeResult writes the result to the file or to stdout for "" and "std"
+func writeResult(resultPath string, result []byte) error {
+	if resultPath == "" || resultPath == "std" {
+		_, err := os.Stdout.Write(result)
+		return err
+	}
+
+	if err := os.WriteFile(resultPath, result, 0644); err != nil {
+		return WrapError(err, "error writing to file")
+	}
+
+	fmt.Printf("Successfully wrote result to %s\n", resultPath)
+	return nil
+}
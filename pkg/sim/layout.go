@@ -0,0 +1,303 @@
+package sim
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rxx/od_tools/data"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultLayoutPath = "layouts/default.yml"
+
+// Layout describes where GameLogCmd finds every value in the sim workbook.
+// The default one is embedded from data/layouts/default.yml and can be
+// replaced with the -layout flag when the upstream workbook changes.
+type Layout struct {
+	Revision     string                 `yaml:"revision"`
+	HeaderRow    int                    `yaml:"header_row"`
+	FirstHourRow int                    `yaml:"first_hour_row"`
+	Fields       map[string]LayoutField `yaml:"fields"`
+	Groups       map[string]LayoutGroup `yaml:"groups"`
+	Stats        map[string]LayoutField `yaml:"stats"`
+}
+
+// LayoutField is a single value, either read per hour from Column or from a fixed Cell.
+// When Label is set the column is looked up by its header text, see Layout.Discover.
+// Unconfirmed labels are not checked against the sim yet, when they are missing the
+// layout column is read with a warning.
+type LayoutField struct {
+	Sheet       string `yaml:"sheet"`
+	Column      string `yaml:"column"`
+	Cell        string `yaml:"cell"`
+	Label       string `yaml:"label"`
+	Unconfirmed bool   `yaml:"unconfirmed"`
+}
+
+// LayoutGroup is a list of columns of the same sheet read together by one action.
+// Unconfirmed applies to the labels of all its columns.
+type LayoutGroup struct {
+	Sheet       string         `yaml:"sheet"`
+	HeaderRow   int            `yaml:"header_row"`
+	Unconfirmed bool           `yaml:"unconfirmed"`
+	Columns     []LayoutColumn `yaml:"columns"`
+}
+
+type LayoutColumn struct {
+	Name        string `yaml:"name"`
+	Column      string `yaml:"column"`
+	Label       string `yaml:"label"`
+	Unconfirmed bool   `yaml:"unconfirmed"`
+	Racial      bool   `yaml:"racial"`
+	Source      string `yaml:"source"`
+	Resource    string `yaml:"resource"`
+	Target      string `yaml:"target"`
+}
+
+var requiredFields = []string{
+	"date", "home_land", "local_time", "dom_time",
+	"draftrate", "previous_draftrate", "release_draftees",
+	"train_platinum_cost", "train_ore_cost",
+	"land_size", "land_bonus", "explore_platinum_cost", "explore_draftee_cost",
+	"rezone_platinum_cost", "construction_platinum_cost", "construction_lumber_cost",
+	"tech_unlocked", "tech_name", "daily_platinum", "peasants",
+	"trade_platinum", "trade_lumber", "trade_ore", "trade_gems",
+}
+
+var requiredGroups = []string{
+	"release", "train", "spells", "explore", "rezone",
+	"construction", "destruction", "improvements",
+}
+
+// Fields and groups only read by the validation rules, they are optional so custom
+// layouts load without them and the rules needing them are skipped with a warning
+var (
+	validationFields = []string{"mana", "mana_production", "population_check"}
+	validationGroups = []string{"units", "buildings"}
+)
+
+// Fields and groups of the cells filled in by the player every hour, the rest are calculated by the sim
+var (
+	inputFields = []string{
+		"draftrate", "release_draftees", "tech_unlocked", "daily_platinum", "land_bonus",
+		"trade_platinum", "trade_lumber", "trade_ore", "trade_gems",
+	}
+	inputGroups = []string{
+		"release", "train", "spells", "explore", "rezone",
+		"construction", "destruction", "improvements",
+	}
+)
+
+// DefaultLayout returns the layout of the sim workbook version the tool is released with.
+func DefaultLayout() (*Layout, error) {
+	content, err := data.FS.ReadFile(defaultLayoutPath)
+	if err != nil {
+		return nil, WrapError(err, "error reading default layout")
+	}
+
+	return ParseLayout(content)
+}
+
+// LoadLayout reads a layout file, empty path returns the default layout.
+func LoadLayout(path string) (*Layout, error) {
+	if path == "" {
+		return DefaultLayout()
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, WrapError(err, "error reading layout file")
+	}
+
+	return ParseLayout(content)
+}
+
+func ParseLayout(content []byte) (*Layout, error) {
+	layout := &Layout{}
+
+	if err := yaml.Unmarshal(content, layout); err != nil {
+		return nil, WrapError(err, "error parsing layout")
+	}
+
+	if err := layout.validate(); err != nil {
+		return nil, err
+	}
+
+	return layout, nil
+}
+
+func (l *Layout) validate() error {
+	if l.FirstHourRow <= 0 {
+		return fmt.Errorf("invalid layout: first_hour_row must be positive")
+	}
+
+	if l.HeaderRow <= 0 || l.HeaderRow >= l.FirstHourRow {
+		return fmt.Errorf("invalid layout: header_row must be between 1 and %d", l.FirstHourRow-1)
+	}
+
+	for _, name := range append(append([]string{}, requiredFields...), validationFields...) {
+		field, ok := l.Fields[name]
+		if !ok {
+			if containsString(requiredFields, name) {
+				return fmt.Errorf("invalid layout: missing field %q", name)
+			}
+			continue
+		}
+		if field.Sheet == "" || (field.Column == "") == (field.Cell == "") {
+			return fmt.Errorf("invalid layout: field %q needs a sheet and either a column or a cell", name)
+		}
+	}
+
+	for name, field := range l.Stats {
+		if field.Sheet == "" || (field.Column == "") == (field.Cell == "") {
+			return fmt.Errorf("invalid layout: stat %q needs a sheet and either a column or a cell", name)
+		}
+	}
+
+	for _, name := range layoutGroups() {
+		group, ok := l.Groups[name]
+		if !ok {
+			if containsString(requiredGroups, name) {
+				return fmt.Errorf("invalid layout: missing group %q", name)
+			}
+			continue
+		}
+		if group.Sheet == "" || len(group.Columns) == 0 {
+			return fmt.Errorf("invalid layout: group %q needs a sheet and columns", name)
+		}
+		for i, col := range group.Columns {
+			if col.Column == "" {
+				return fmt.Errorf("invalid layout: group %q has a column without letter", name)
+			}
+			if col.Name == "" {
+				group.Columns[i].Name = col.Label
+			}
+		}
+	}
+
+	return nil
+}
+
+// Field returns a field definition, it's always present after validation.
+func (l *Layout) Field(name string) LayoutField {
+	return l.Fields[name]
+}
+
+// Group returns a group definition, it's always present after validation.
+func (l *Layout) Group(name string) LayoutGroup {
+	group := l.Groups[name]
+	if group.HeaderRow == 0 {
+		group.HeaderRow = l.HeaderRow
+	}
+
+	return group
+}
+
+// layoutEntry is a labelled field or group column
+type layoutEntry struct {
+	Name        string
+	Sheet       string
+	Label       string
+	Column      string
+	Unconfirmed bool
+}
+
+// labelledEntries returns fields and group columns with a label in a stable order
+func (l *Layout) labelledEntries() []layoutEntry {
+	entries := []layoutEntry{}
+
+	for _, groupName := range layoutGroups() {
+		group := l.Groups[groupName]
+		for _, col := range group.Columns {
+			if col.Label != "" {
+				entries = append(entries, layoutEntry{groupName + " " + col.Name, group.Sheet, col.Label, col.Column, group.Unconfirmed || col.Unconfirmed})
+			}
+		}
+	}
+
+	for _, name := range l.fieldNames() {
+		field := l.Fields[name]
+		if field.Label != "" && field.Column != "" {
+			entries = append(entries, layoutEntry{name, field.Sheet, field.Label, field.Column, field.Unconfirmed})
+		}
+	}
+
+	return entries
+}
+
+// inputEntries returns the per-hour columns filled in by the player in a stable order
+func (l *Layout) inputEntries() []layoutEntry {
+	entries := []layoutEntry{}
+
+	for _, groupName := range inputGroups {
+		group := l.Groups[groupName]
+		for _, col := range group.Columns {
+			entries = append(entries, layoutEntry{Name: groupName + " " + col.Name, Sheet: group.Sheet, Label: col.Label, Column: col.Column})
+		}
+	}
+
+	for _, name := range inputFields {
+		field := l.Fields[name]
+		entries = append(entries, layoutEntry{Name: name, Sheet: field.Sheet, Label: field.Label, Column: field.Column})
+	}
+
+	return entries
+}
+
+// clone returns a copy of the layout that can be changed without changing the layout
+func (l *Layout) clone() *Layout {
+	copied := *l
+
+	copied.Fields = make(map[string]LayoutField, len(l.Fields))
+	for name, field := range l.Fields {
+		copied.Fields[name] = field
+	}
+
+	copied.Groups = make(map[string]LayoutGroup, len(l.Groups))
+	for name, group := range l.Groups {
+		group.Columns = append([]LayoutColumn{}, group.Columns...)
+		copied.Groups[name] = group
+	}
+
+	copied.Stats = make(map[string]LayoutField, len(l.Stats))
+	for name, field := range l.Stats {
+		copied.Stats[name] = field
+	}
+
+	return &copied
+}
+
+// layoutGroups returns the names of the groups read from the sim, required ones first
+func layoutGroups() []string {
+	return append(append([]string{}, requiredGroups...), validationGroups...)
+}
+
+func (l *Layout) fieldNames() []string {
+	names := make([]string, 0, len(l.Fields))
+	for name := range l.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// sheets returns the sheet names used by the layout
+func (l *Layout) sheets() []string {
+	sheets := []string{}
+	add := func(sheet string) {
+		if sheet != "" && !containsString(sheets, sheet) {
+			sheets = append(sheets, sheet)
+		}
+	}
+
+	for _, groupName := range requiredGroups {
+		add(l.Groups[groupName].Sheet)
+	}
+	for _, name := range l.fieldNames() {
+		add(l.Fields[name].Sheet)
+	}
+
+	return sheets
+}
@@ -0,0 +1,62 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultLayout(t *testing.T) {
+	layout, err := DefaultLayout()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if layout.FirstHourRow != 4 {
+		t.Errorf("Incorrect first hour row: got %d, want %d", layout.FirstHourRow, 4)
+	}
+
+	if got := layout.Group("release").HeaderRow; got != 2 {
+		t.Errorf("Incorrect group header row: got %d, want %d", got, 2)
+	}
+
+	construction := layout.Group("construction")
+	if len(construction.Columns) != 18 || construction.Columns[2].Name != "Farms" || construction.Columns[2].Column != "Q" {
+		t.Errorf("Incorrect construction group: %+v", construction.Columns)
+	}
+}
+
+func TestParseLayoutErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:        "Invalid YAML",
+			content:     "fields: [",
+			expectedErr: "error parsing layout",
+		},
+		{
+			name:        "Missing Rows",
+			content:     "fields: {}",
+			expectedErr: "first_hour_row must be positive",
+		},
+		{
+			name:        "Missing Field",
+			content:     "header_row: 2\nfirst_hour_row: 4\nfields: {}",
+			expectedErr: "missing field \"date\"",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseLayout([]byte(tc.content))
+			if err == nil {
+				t.Fatalf("Expected error: %v, but got none", tc.expectedErr)
+			}
+			if !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("Incorrect error message: got %q, want %q", err, tc.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,496 @@
+package sim
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Log2SimCmd writes the actions of an import log into the input cells of a sim template
+type Log2SimCmd struct {
+	logPath string
+	simPath string
+	outPath string
+	clear   bool
+	layout  *Layout
+	options GameLogOptions
+}
+
+// Log2SimResult is the number of written cells and actions that have no cell in the sim
+type Log2SimResult struct {
+	Cells    int
+	Warnings []string
+}
+
+// simInput is a value waiting to be written, amounts of the same cell are added up
+type simInput struct {
+	sheet string
+	cell  string
+	value interface{}
+}
+
+func NewLog2SimCmd(logPath, simPath, outPath string, clear bool, options GameLogOptions) (*Log2SimCmd, error) {
+	if err := checkOutPath(simPath, outPath); err != nil {
+		return nil, err
+	}
+
+	layout, err := LoadLayout(options.LayoutPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Log2SimCmd{
+		logPath: logPath,
+		simPath: simPath,
+		outPath: outPath,
+		clear:   clear,
+		layout:  layout,
+		options: options,
+	}, nil
+}
+
+func (c *Log2SimCmd) Execute() error {
+	file, err := os.Open(c.logPath)
+	if err != nil {
+		return WrapError(err, "error reading log file")
+	}
+	defer file.Close()
+
+	parsed, err := ParseLog(file)
+	if err != nil {
+		return WrapError(err, "error parsing log")
+	}
+
+	return fillSim(c.simPath, c.outPath, c.layout, c.options, c.clear, parsed.Results())
+}
+
+// fillSim writes actions into a copy of the sim saved to outPath, warnings go to stderr
+func fillSim(simPath, outPath string, layout *Layout, options GameLogOptions, clear bool, results map[int][]ActionResult) error {
+	// Only excelize keeps the rest of the workbook including macros when it's saved
+	workbook, err := excelize.OpenFile(simPath)
+	if err != nil {
+		return WrapError(err, "error on opening sim file")
+	}
+	defer workbook.Close()
+
+	if !options.SkipDiscovery {
+		if layout, err = discoverLayout(layout, workbook, simPath); err != nil {
+			return err
+		}
+	}
+
+	if clear {
+		if _, err := ClearInputs(workbook, layout, ClearByLayout); err != nil {
+			return err
+		}
+	}
+
+	result, err := WriteLogToSim(workbook, layout, results)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	if err := workbook.SaveAs(outPath); err != nil {
+		return WrapError(err, "error saving sim")
+	}
+
+	fmt.Printf("Successfully wrote %d cells to %s, open it in Excel to recalculate formulas\n", result.Cells, outPath)
+
+	return nil
+}
+
+// WriteLogToSim fills the per-hour input cells from parsed log or plan actions, keys of
+// results are protection hours starting from 0. Cells with formulas are never
+// changed, actions without a matching cell are reported as warnings.
+func WriteLogToSim(workbook *excelize.File, layout *Layout, results map[int][]ActionResult) (*Log2SimResult, error) {
+	writer := &simInputWriter{
+		workbook: workbook,
+		layout:   layout,
+		values:   map[string]*simInput{},
+		result:   &Log2SimResult{Warnings: []string{}},
+	}
+
+	keys := make([]int, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	for _, key := range keys {
+		hr := key + 1
+		if hr < 1 || hr > LastHour {
+			writer.warn(hr, "hour is out of protection, %d actions skipped", len(results[key]))
+			continue
+		}
+
+		for _, action := range results[key] {
+			if err := writer.writeAction(hr, action); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writer.flush(); err != nil {
+		return nil, err
+	}
+
+	return writer.result, nil
+}
+
+type simInputWriter struct {
+	workbook *excelize.File
+	layout   *Layout
+	values   map[string]*simInput
+	order    []string
+	result   *Log2SimResult
+}
+
+func (w *simInputWriter) warn(hr int, format string, args ...interface{}) {
+	w.result.Warnings = append(w.result.Warnings, fmt.Sprintf("Hour %d: %s", hr, fmt.Sprintf(format, args...)))
+}
+
+func (w *simInputWriter) cell(column string, hr int) string {
+	return fmt.Sprintf("%s%d", column, hr+w.layout.FirstHourRow-1)
+}
+
+// set replaces the value of a cell, add sums it with the value of earlier actions
+func (w *simInputWriter) set(sheet, cell string, value interface{}) {
+	key := sheet + "!" + cell
+	if _, ok := w.values[key]; !ok {
+		w.order = append(w.order, key)
+	}
+	w.values[key] = &simInput{sheet: sheet, cell: cell, value: value}
+}
+
+func (w *simInputWriter) add(sheet, cell string, value int) {
+	if input, ok := w.values[sheet+"!"+cell]; ok {
+		if previous, ok := input.value.(int); ok {
+			value += previous
+		}
+	}
+	w.set(sheet, cell, value)
+}
+
+func (w *simInputWriter) addField(name string, hr, value int) {
+	field := w.layout.Field(name)
+	w.add(field.Sheet, w.cell(field.Column, hr), value)
+}
+
+func (w *simInputWriter) writeAction(hr int, action ActionResult) error {
+	switch action.Type {
+	case DRAFTRATE:
+		return w.writeDraftRate(hr, action.Data["value"])
+	case RELEASE:
+		data := ActionResultData{}
+		for name, value := range action.Data {
+			if name == resultKey("draftees") {
+				w.addField("release_draftees", hr, value)
+				continue
+			}
+			data[name] = value
+		}
+		return w.writeAmounts(hr, "release", true, data)
+	case TRAIN:
+		return w.writeAmounts(hr, "train", true, action.Data)
+	case EXPLORE:
+		return w.writeAmounts(hr, "explore", false, action.Data)
+	case REZONE:
+		return w.writeAmounts(hr, "rezone", false, action.Data)
+	case CONSTRUCTION:
+		return w.writeAmounts(hr, "construction", false, action.Data)
+	case DESTRUCTION:
+		return w.writeAmounts(hr, "destruction", false, action.Data)
+	case MAGIC:
+		return w.writeSpell(hr, action.Name)
+	case TECH:
+		return w.writeTech(hr, action.Name)
+	case DAILY:
+		for _, name := range sortedKeys(action.Data) {
+			switch {
+			case name == "platinum":
+				w.addField("daily_platinum", hr, 1)
+			case w.isLand(name):
+				w.addField("land_bonus", hr, 1)
+			default:
+				return fmt.Errorf("hour %d: unknown daily bonus %q", hr, name)
+			}
+		}
+	case BANK:
+		for _, name := range sortedKeys(action.Data) {
+			if _, ok := w.layout.Fields["trade_"+name]; !ok {
+				w.warn(hr, "can't trade %s", name)
+				continue
+			}
+			w.addField("trade_"+name, hr, action.Data[name])
+		}
+	case INVEST:
+		w.writeInvestment(hr, action)
+	default:
+		w.warn(hr, "unknown action %s", action.Type)
+	}
+
+	return nil
+}
+
+// writeDraftRate writes the rate as a fraction, the cell gets a percent format
+// if it doesn't have one so the sim shows it the same way as the game
+func (w *simInputWriter) writeDraftRate(hr, rate int) error {
+	field := w.layout.Field("draftrate")
+	cell := w.cell(field.Column, hr)
+
+	if err := setPercentFormat(w.workbook, field.Sheet, cell); err != nil {
+		return err
+	}
+
+	w.set(field.Sheet, cell, float64(rate)/100)
+
+	return nil
+}
+
+// writeAmounts adds amounts to the group columns of the same name, units are
+// named by the header row of the sim
+func (w *simInputWriter) writeAmounts(hr int, groupName string, nameFromHeader bool, data ActionResultData) error {
+	group := w.layout.Group(groupName)
+
+	columns := map[string]string{}
+	for _, col := range group.Columns {
+		name := col.Name
+		if nameFromHeader {
+			header, err := w.workbook.GetCellValue(group.Sheet, fmt.Sprintf("%s%d", col.Column, group.HeaderRow))
+			if err != nil {
+				return WrapError(err, "error reading unit name")
+			}
+			name = header
+		}
+		if name = normalizeName(name); name != "" {
+			if _, ok := columns[name]; !ok {
+				columns[name] = col.Column
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(data) {
+		if strings.HasPrefix(name, "cost_") {
+			continue
+		}
+
+		column, ok := columns[normalizeName(name)]
+		if !ok {
+			w.warn(hr, "no %s column for %s", groupName, name)
+			continue
+		}
+
+		w.add(group.Sheet, w.cell(column, hr), data[name])
+	}
+
+	return nil
+}
+
+// writeSpell checks the column of the spell, racial spells are found by the header of
+// the racial columns. "Racial Spell" and the racial spell of the race of the sim go to
+// the first racial column when no header names them, other spells are an error.
+func (w *simInputWriter) writeSpell(hr int, spell string) error {
+	group := w.layout.Group("spells")
+
+	racialColumn := ""
+	for _, col := range group.Columns {
+		if col.Racial {
+			header, err := w.workbook.GetCellValue(group.Sheet, fmt.Sprintf("%s%d", col.Column, group.HeaderRow))
+			if err != nil {
+				return WrapError(err, "error reading spell name")
+			}
+			if normalizeName(header) == normalizeName(spell) {
+				w.set(group.Sheet, w.cell(col.Column, hr), 1)
+				return nil
+			}
+			if racialColumn == "" {
+				racialColumn = col.Column
+			}
+			continue
+		}
+
+		if normalizeName(col.Name) == normalizeName(spell) {
+			w.set(group.Sheet, w.cell(col.Column, hr), 1)
+			return nil
+		}
+	}
+
+	if racialColumn == "" || !w.isRacialSpell(spell) {
+		return fmt.Errorf("hour %d: unknown spell %q, the sim has no column for it", hr, spell)
+	}
+
+	w.set(group.Sheet, w.cell(racialColumn, hr), 1)
+	return nil
+}
+
+// isRacialSpell tells if the spell is "Racial Spell" or the racial spell of the race
+// set in the sim, a sim without a known race has no racial spell
+func (w *simInputWriter) isRacialSpell(spell string) bool {
+	if normalizeName(spell) == normalizeName(RacialSpell) {
+		return true
+	}
+
+	field, ok := w.layout.Fields["race"]
+	if !ok {
+		return false
+	}
+	race, err := w.workbook.GetCellValue(field.Sheet, field.Cell)
+	if err != nil || strings.TrimSpace(race) == "" {
+		return false
+	}
+
+	spells, err := LoadSpells()
+	if err != nil {
+		return false
+	}
+	races, err := LoadRaces()
+	if err != nil {
+		return false
+	}
+	racial, err := FindRacialSpell(spells, races, strings.TrimSpace(race))
+	if err != nil {
+		return false
+	}
+
+	return normalizeName(racial.Name) == normalizeName(spell)
+}
+
+// isLand tells if the daily land bonus is named by a land of the explore columns,
+// plans name it just "land"
+func (w *simInputWriter) isLand(name string) bool {
+	if name == "land" {
+		return true
+	}
+
+	for _, col := range w.layout.Group("explore").Columns {
+		if normalizeName(col.Name) == normalizeName(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeTech checks the unlock column, the tech name is written only when the sim
+// doesn't pick it by a formula
+func (w *simInputWriter) writeTech(hr int, tech string) error {
+	unlocked := w.layout.Field("tech_unlocked")
+	w.set(unlocked.Sheet, w.cell(unlocked.Column, hr), 1)
+
+	name := w.layout.Field("tech_name")
+	if name.Column == "" {
+		return nil
+	}
+
+	cell := w.cell(name.Column, hr)
+	formula, err := w.workbook.GetCellFormula(name.Sheet, cell)
+	if err != nil {
+		return WrapError(err, "error reading formula")
+	}
+	if formula == "" {
+		w.set(name.Sheet, cell, tech)
+	}
+
+	return nil
+}
+
+// writeInvestment fills the first free improvements row of the hour
+func (w *simInputWriter) writeInvestment(hr int, action ActionResult) {
+	group := w.layout.Group("improvements")
+
+	for _, resource := range sortedKeys(action.Data) {
+		written := false
+		for _, col := range group.Columns {
+			cell := w.cell(col.Column, hr)
+			if _, ok := w.values[group.Sheet+"!"+cell]; ok {
+				continue
+			}
+
+			w.set(group.Sheet, cell, action.Data[resource])
+			if col.Resource != "" {
+				w.set(group.Sheet, w.cell(col.Resource, hr), resource)
+			}
+			if col.Target != "" {
+				w.set(group.Sheet, w.cell(col.Target, hr), action.Name)
+			}
+			written = true
+			break
+		}
+
+		if !written {
+			w.warn(hr, "no free improvements column for %d %s into %s", action.Data[resource], resource, action.Name)
+		}
+	}
+}
+
+// flush writes the values in the order of actions, formulas are kept
+func (w *simInputWriter) flush() error {
+	for _, key := range w.order {
+		input := w.values[key]
+
+		formula, err := w.workbook.GetCellFormula(input.sheet, input.cell)
+		if err != nil {
+			return WrapError(err, "error reading formula")
+		}
+		if formula != "" {
+			w.result.Warnings = append(w.result.Warnings,
+				fmt.Sprintf("%s!%s has a formula, %v is not written", input.sheet, input.cell, input.value))
+			continue
+		}
+
+		if err := w.workbook.SetCellValue(input.sheet, input.cell, input.value); err != nil {
+			return WrapError(err, fmt.Sprintf("error writing %s!%s", input.sheet, input.cell))
+		}
+		w.result.Cells++
+	}
+
+	return nil
+}
+
+// setPercentFormat changes the number format of a cell to percents, the rest of its style is kept
+func setPercentFormat(workbook *excelize.File, sheet, cell string) error {
+	styleID, err := workbook.GetCellStyle(sheet, cell)
+	if err != nil {
+		return WrapError(err, "error reading cell style")
+	}
+
+	style, err := workbook.GetStyle(styleID)
+	if err != nil {
+		return WrapError(err, "error reading cell style")
+	}
+
+	if style.NumFmt == 9 || style.NumFmt == 10 || (style.CustomNumFmt != nil && strings.Contains(*style.CustomNumFmt, "%")) {
+		return nil
+	}
+
+	style.NumFmt = 9
+	style.CustomNumFmt = nil
+	percentID, err := workbook.NewStyle(style)
+	if err != nil {
+		return WrapError(err, "error creating percent style")
+	}
+
+	return workbook.SetCellStyle(sheet, cell, cell, percentID)
+}
+
+// normalizeName compares names of the log and the sim the same way as the log parser
+func normalizeName(name string) string {
+	return strings.ToLower(resultKey(strings.TrimSpace(name)))
+}
+
+func sortedKeys(data ActionResultData) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
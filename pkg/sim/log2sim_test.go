@@ -0,0 +1,192 @@
+package sim
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func newLog2SimWorkbook(t *testing.T) *excelize.File {
+	workbook := excelize.NewFile()
+	for _, sheet := range append(mustDefaultLayout().sheets(), Constants) {
+		workbook.NewSheet(sheet)
+	}
+
+	headers := map[string]string{
+		"AX2": "Satyr", "AY2": "Spies", // release
+		"AG2": "Satyr", "AL2": "Archspies", // train
+	}
+	for cell, value := range headers {
+		if err := workbook.SetCellValue(Military, cell, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := workbook.SetCellValue(Magic, "L2", "Mechanical Genius"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tech name of the hour is picked by a formula
+	workbook.SetCellFormula(Techs, "CA6", "LOOKUP(K6)")
+
+	return workbook
+}
+
+func TestWriteLogToSim(t *testing.T) {
+	workbook := newLog2SimWorkbook(t)
+	defer workbook.Close()
+
+	results := map[int][]ActionResult{
+		0: {
+			{Type: DRAFTRATE, Data: ActionResultData{"value": 90}},
+			{Type: RELEASE, Data: ActionResultData{"Satyr": 3}},
+			{Type: RELEASE, Data: ActionResultData{"draftees": 7}},
+			{Type: MAGIC, Name: "Gaia's Watch", Data: ActionResultData{"mana": 578}},
+			{Type: MAGIC, Name: "Mechanical Genius", Data: ActionResultData{"mana": 578}},
+			{Type: DAILY, Data: ActionResultData{"platinum": 4004}},
+			{Type: BANK, Data: ActionResultData{"platinum": -1000, "lumber": 500}},
+		},
+		2: {
+			{Type: EXPLORE, Data: ActionResultData{"Plains": 5, "Forest": 20, "cost_platinum": 30000}},
+			{Type: DAILY, Data: ActionResultData{"Forest": 20}},
+			{Type: DESTRUCTION, Data: ActionResultData{"Farms": 2}},
+			{Type: REZONE, Data: ActionResultData{"Plains": -2, "Forest": 2}},
+			{Type: CONSTRUCTION, Data: ActionResultData{"Homes": 5, "lumberyard": 10}},
+			{Type: CONSTRUCTION, Data: ActionResultData{"Homes": 1}},
+			{Type: TRAIN, Data: ActionResultData{"Satyr": 100, "assassins": 5}},
+			{Type: TECH, Name: "Treasure Hunt", Data: ActionResultData{}},
+			{Type: INVEST, Name: "walls", Data: ActionResultData{"lumber": 5000}},
+			{Type: INVEST, Name: "keep", Data: ActionResultData{"platinum": 1000}},
+			{Type: TRAIN, Data: ActionResultData{"Unicorn": 1}},
+		},
+		80: {
+			{Type: DAILY, Data: ActionResultData{"platinum": 4004}},
+		},
+	}
+
+	result, err := WriteLogToSim(workbook, mustDefaultLayout(), results)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"Military!Y4":      "90%",
+		"Military!AX4":     "3",
+		"Military!AW4":     "7",
+		"Magic!G4":         "1",
+		"Magic!L4":         "1",
+		"Production!C4":    "1",
+		"Production!BC4":   "-1000",
+		"Production!BD4":   "500",
+		"Explore!T6":       "5",
+		"Explore!U6":       "20",
+		"Explore!S6":       "1",
+		"Construction!BY6": "2",
+		"Rezone!L6":        "-2",
+		"Rezone!M6":        "2",
+		"Construction!O6":  "6",
+		"Construction!T6":  "10",
+		"Military!AG6":     "100",
+		"Military!AL6":     "5",
+		"Techs!K6":         "1",
+		"Imps!P6":          "5000",
+		"Imps!O6":          "lumber",
+		"Imps!Q6":          "walls",
+		"Imps!S6":          "1000",
+		"Imps!R6":          "platinum",
+		"Imps!T6":          "keep",
+	}
+
+	for ref, want := range expected {
+		sheet, cell := splitRef(ref)
+		value, err := workbook.GetCellValue(sheet, cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != want {
+			t.Errorf("%s should be %q, got %q", ref, want, value)
+		}
+	}
+
+	if formula, _ := workbook.GetCellFormula(Techs, "CA6"); formula != "LOOKUP(K6)" {
+		t.Errorf("Formula of the tech name should be kept, got %q", formula)
+	}
+
+	if result.Cells != len(expected) {
+		t.Errorf("Expected %d written cells, got %d", len(expected), result.Cells)
+	}
+
+	expectedWarnings := []string{
+		"Hour 3: no train column for Unicorn",
+		"Hour 81: hour is out of protection, 1 actions skipped",
+	}
+	if !reflect.DeepEqual(result.Warnings, expectedWarnings) {
+		t.Errorf("Incorrect warnings:\n got %q\nwant %q", result.Warnings, expectedWarnings)
+	}
+}
+
+func TestWriteLogToSimSpells(t *testing.T) {
+	workbook := newLog2SimWorkbook(t)
+	defer workbook.Close()
+	if err := workbook.SetCellValue(Overview, "B14", "Sylvan"); err != nil {
+		t.Fatal(err)
+	}
+
+	results := map[int][]ActionResult{
+		0: {{Type: MAGIC, Name: "Racial Spell", Data: ActionResultData{}}},
+		1: {{Type: MAGIC, Name: "Verdant Bloom", Data: ActionResultData{"mana": 1155}}},
+	}
+	if _, err := WriteLogToSim(workbook, mustDefaultLayout(), results); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, cell := range []string{"L4", "L5"} {
+		if value, _ := workbook.GetCellValue(Magic, cell); value != "1" {
+			t.Errorf("Racial spell should be cast in Magic!%s, got %q", cell, value)
+		}
+	}
+}
+
+func TestWriteLogToSimErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		action      ActionResult
+		expectedErr string
+	}{
+		{
+			name:        "Unknown Spell",
+			action:      ActionResult{Type: MAGIC, Name: "Fireball", Data: ActionResultData{}},
+			expectedErr: `hour 1: unknown spell "Fireball"`,
+		},
+		{
+			name:        "Unknown Daily Bonus",
+			action:      ActionResult{Type: DAILY, Data: ActionResultData{"gems": 100}},
+			expectedErr: `hour 1: unknown daily bonus "gems"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			workbook := newLog2SimWorkbook(t)
+			defer workbook.Close()
+
+			_, err := WriteLogToSim(workbook, mustDefaultLayout(), map[int][]ActionResult{0: {tc.action}})
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("Expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestNewLog2SimCmd(t *testing.T) {
+	simPath := filepath.Join(t.TempDir(), "sim.xlsm")
+
+	if _, err := NewLog2SimCmd("log.txt", simPath, "", false, GameLogOptions{}); err == nil {
+		t.Error("Expected an error without -out")
+	}
+	if _, err := NewLog2SimCmd("log.txt", simPath, simPath, false, GameLogOptions{}); err == nil {
+		t.Error("Expected an error when -out is the sim")
+	}
+}
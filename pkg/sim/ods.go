@@ -0,0 +1,209 @@
+package sim
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	odsContentFile = "content.xml"
+
+	odsOfficeNS = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odsTableNS  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsTextNS   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// OpenODS reads a LibreOffice Calc sim. Cells have the text displayed by Calc,
+// like formatted values returned by excelize, so both backends parse the same.
+func OpenODS(path string) (Sim, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, WrapError(err, "error on opening ods sim file")
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name != odsContentFile {
+			continue
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			return nil, WrapError(err, "error on opening ods sim file")
+		}
+		defer content.Close()
+
+		return parseODSContent(content)
+	}
+
+	return nil, fmt.Errorf("error on opening ods sim file: %s has no %s", path, odsContentFile)
+}
+
+// odsCell is a cell being read with its repeat count, a merged cell spans more than
+// one column or row
+type odsCell struct {
+	value   string
+	repeat  int
+	colSpan int
+	rowSpan int
+}
+
+// odsMerge is a merged range, its covered cells have the value of the top left cell
+// like in excelize
+type odsMerge struct {
+	sheet    string
+	row, col int
+	rows     int
+	cols     int
+	value    string
+}
+
+func parseODSContent(r io.Reader) (*gridSim, error) {
+	grid := newGridSim()
+	decoder := xml.NewDecoder(r)
+
+	var (
+		sheet      string
+		row        int
+		rowRepeat  int
+		rowCells   []odsCell
+		cell       *odsCell
+		paragraphs []string
+		inText     bool
+		merges     []odsMerge
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, WrapError(err, "error parsing ods content")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == odsOfficeNS && t.Name.Local == "annotation":
+				// Comments have paragraphs too, they're not cell values
+				if err := decoder.Skip(); err != nil {
+					return nil, WrapError(err, "error parsing ods content")
+				}
+
+			case t.Name.Space == odsTableNS && t.Name.Local == "table":
+				sheet = odsAttr(t, odsTableNS, "name")
+				grid.addSheet(sheet)
+				row = 0
+
+			case t.Name.Space == odsTableNS && t.Name.Local == "table-row":
+				rowRepeat = odsCount(t, odsTableNS, "number-rows-repeated")
+				rowCells = nil
+
+			case t.Name.Space == odsTableNS && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				cell = &odsCell{
+					value:   odsAttr(t, odsOfficeNS, "value"),
+					repeat:  odsCount(t, odsTableNS, "number-columns-repeated"),
+					colSpan: odsCount(t, odsTableNS, "number-columns-spanned"),
+					rowSpan: odsCount(t, odsTableNS, "number-rows-spanned"),
+				}
+				paragraphs = nil
+
+			case cell != nil && t.Name.Space == odsTextNS:
+				switch t.Name.Local {
+				case "p":
+					paragraphs = append(paragraphs, "")
+					inText = true
+				case "s":
+					count := odsCount(t, odsTextNS, "c")
+					paragraphs[len(paragraphs)-1] += strings.Repeat(" ", count)
+				case "tab":
+					paragraphs[len(paragraphs)-1] += "\t"
+				case "line-break":
+					paragraphs[len(paragraphs)-1] += "\n"
+				}
+			}
+
+		case xml.CharData:
+			if inText {
+				paragraphs[len(paragraphs)-1] += string(t)
+			}
+
+		case xml.EndElement:
+			switch {
+			case t.Name.Space == odsTextNS && t.Name.Local == "p":
+				inText = false
+
+			case t.Name.Space == odsTableNS && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				if len(paragraphs) > 0 {
+					cell.value = strings.Join(paragraphs, "\n")
+				}
+				rowCells = append(rowCells, *cell)
+				cell = nil
+
+			case t.Name.Space == odsTableNS && t.Name.Local == "table-row":
+				if !odsRowHasValues(rowCells) {
+					row += rowRepeat
+					continue
+				}
+
+				for i := 0; i < rowRepeat; i++ {
+					row++
+					col := 0
+					for _, rowCell := range rowCells {
+						for j := 0; j < rowCell.repeat; j++ {
+							col++
+							grid.setCell(sheet, row, col, rowCell.value)
+
+							if rowCell.colSpan > 1 || rowCell.rowSpan > 1 {
+								merges = append(merges, odsMerge{sheet, row, col, rowCell.rowSpan, rowCell.colSpan, rowCell.value})
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Covered cells are read after their rows, they are empty in the content
+	for _, merge := range merges {
+		for row := merge.row; row < merge.row+merge.rows; row++ {
+			for col := merge.col; col < merge.col+merge.cols; col++ {
+				grid.setCell(merge.sheet, row, col, merge.value)
+			}
+		}
+	}
+
+	return grid, nil
+}
+
+func odsRowHasValues(cells []odsCell) bool {
+	for _, cell := range cells {
+		if cell.value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func odsAttr(element xml.StartElement, space, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Space == space && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// odsCount reads a repeat count attribute, it's 1 when missing
+func odsCount(element xml.StartElement, space, name string) int {
+	count, err := strconv.Atoi(odsAttr(element, space, name))
+	if err != nil || count < 1 {
+		return 1
+	}
+	return count
+}
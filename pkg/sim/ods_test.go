@@ -0,0 +1,108 @@
+package sim
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const odsTestContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content
+	xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet>
+<table:table table:name="Overview">
+	<table:table-row>
+		<table:table-cell office:value-type="string"><text:p>Race</text:p></table:table-cell>
+		<table:table-cell office:value-type="string">
+			<text:p>Sylvan<text:s text:c="2"/>Elf</text:p>
+			<office:annotation><text:p>comment</text:p></office:annotation>
+		</table:table-cell>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="2"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+	<table:table-row>
+		<table:table-cell table:number-columns-repeated="2"/>
+		<table:table-cell office:value-type="float" office:value="1234.5"><text:p>1,235</text:p></table:table-cell>
+		<table:table-cell office:value-type="float" office:value="7"/>
+		<table:table-cell office:value-type="percentage" office:value="0.35" table:number-columns-repeated="2"><text:p>35%</text:p></table:table-cell>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>
+<table:table table:name="Imps">
+	<table:table-header-rows>
+		<table:table-row><table:table-cell><text:p>Line</text:p><text:p>Two</text:p></table:table-cell></table:table-row>
+	</table:table-header-rows>
+	<table:table-row>
+		<table:table-cell table:number-columns-spanned="2" table:number-rows-spanned="2" office:value-type="string"><text:p>Local Time</text:p></table:table-cell>
+		<table:covered-table-cell/>
+		<table:table-cell office:value-type="string"><text:p>Domtime</text:p></table:table-cell>
+	</table:table-row>
+	<table:table-row>
+		<table:covered-table-cell table:number-columns-repeated="2"/>
+		<table:table-cell/>
+	</table:table-row>
+</table:table>
+</office:spreadsheet></office:body>
+</office:document-content>`
+
+func TestOpenODS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sim.ods")
+	writeTestODS(t, path, odsTestContent)
+
+	sim, err := OpenSim(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer sim.Close()
+
+	testCases := []struct {
+		sheet    string
+		cell     string
+		expected string
+	}{
+		{Overview, "A1", "Race"},
+		{Overview, "B1", "Sylvan  Elf"},
+		{Overview, "A2", ""},
+		{Overview, "C4", "1,235"},
+		{Overview, "D4", "7"},
+		{Overview, "E4", "35%"},
+		{Overview, "F4", "35%"},
+		{Overview, "G4", ""},
+		{Overview, "A100", ""},
+		{Imps, "A1", "Line\nTwo"},
+		// Covered cells of a merged header have its value like in excelize
+		{Imps, "A2", "Local Time"},
+		{Imps, "B2", "Local Time"},
+		{Imps, "A3", "Local Time"},
+		{Imps, "B3", "Local Time"},
+		{Imps, "C2", "Domtime"},
+		{Imps, "C3", ""},
+	}
+
+	for _, tc := range testCases {
+		value, err := sim.GetCellValue(tc.sheet, tc.cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value != tc.expected {
+			t.Errorf("Incorrect %s!%s value: got %q, want %q", tc.sheet, tc.cell, value, tc.expected)
+		}
+	}
+
+	if _, err := sim.GetCellValue("Missing", "A1"); err == nil {
+		t.Errorf("Expected error for a missing sheet")
+	}
+
+	sheets := sim.(SheetLister).GetSheetList()
+	if strings.Join(sheets, ",") != "Overview,Imps" {
+		t.Errorf("Incorrect sheets: %v", sheets)
+	}
+}
+
+func writeTestODS(t *testing.T, path, content string) {
+	writeTestZip(t, path, map[string]string{
+		"mimetype":     "application/vnd.oasis.opendocument.spreadsheet",
+		odsContentFile: content,
+	})
+}
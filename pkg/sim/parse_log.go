@@ -2,13 +2,15 @@ package sim
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
-	"runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -22,6 +24,7 @@ const (
 	MAGIC        = "magic"
 	RELEASE      = "release"
 	REZONE       = "rezone"
+	TECH         = "tech"
 	TRAIN        = "train"
 )
 
@@ -52,235 +55,584 @@ var valuesMap = map[string]string{
 	"Miners Sight":   "Miner's Sight",
 }
 
-type Scanner interface {
-	Scan() bool
-	Text() string
-	Err() error
-}
-
-type LogFile interface {
-	Close() error
-}
+// Log lines, see renderEvent for the generated ones. Numbers of the game log can have thousands separators.
+var (
+	hourPattern         = regexp.MustCompile(`Protection Hour: (\d+)`)
+	hourTimesPattern    = regexp.MustCompile(`\( Local Time: (.+?) \) \( Domtime: (.+?) \)`)
+	draftratePattern    = regexp.MustCompile(`Draftrate changed to (\d+)%`)
+	releasePattern      = regexp.MustCompile(`^You successfully released (.+?)\.?$`)
+	spellPattern        = regexp.MustCompile(`^Your wizards successfully cast (.+) at a cost of ([\d,]+) mana`)
+	techPattern         = regexp.MustCompile(`^You have unlocked (.+?)\.?$`)
+	dailyBonusPattern   = regexp.MustCompile(`^You have been awarded with ([\d,]+) (.+?)\.?$`)
+	tradePattern        = regexp.MustCompile(`^(.+) have been traded for (.+?)\.?$`)
+	explorePattern      = regexp.MustCompile(`^Exploration for (.+) begun at a cost of (.+?)\.?$`)
+	destructionPattern  = regexp.MustCompile(`^Destruction of (.+) is complete`)
+	rezonePattern       = regexp.MustCompile(`^Rezoning begun at a cost of (.+?)\. The changes in land are as following: (.+?)\.?$`)
+	constructionPattern = regexp.MustCompile(`^Construction of (.+) started at a cost of (.+?)\.?$`)
+	trainPattern        = regexp.MustCompile(`^Training of (.+) begun at a cost of (.+?)\.?$`)
+	investPattern       = regexp.MustCompile(`^You invested ([\d,]+) (.+) into (.+?)\.?$`)
+	amountPattern       = regexp.MustCompile(`^(-?[\d,]+) (.+)$`)
+	costsSeparator      = regexp.MustCompile(`,? and |, `)
+)
 
 type ParseLogFunc func() error
 
+var errUnknownLine = errors.New("unknown action")
+
 type ActionResultData map[string]int
 
+// ActionResult is the record of an action written by parse_log and generate_log
+// -format json, log2sim fills sims from them. Name is set for named actions like
+// spells, techs and improvements, Data is keyed by the names of the game through
+// valuesMap. EventResults converts events to results.
 type ActionResult struct {
-	Type string
-	Data map[string]int
+	Type string         `json:"type" yaml:"type"`
+	Name string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Data map[string]int `json:"data" yaml:"data"`
 }
 
-type LogCmd struct {
-	currentHour   int
-	logPath       string
-	resultPath    string
-	scanner       Scanner
-	file          LogFile
-	currentText   string
-	lineNumber    int
-	actionResults map[int][]ActionResult
-	actions       []ParseLogFunc
+// ParsedLog is an import log read by ParseLog, hours are in the order of the log and
+// their events in the order they are imported. Events have the names of the log,
+// times of hours without them in the hour line are zero.
+type ParsedLog struct {
+	Hours  []*HourLog
+	Errors []ParseError
+}
+
+// LogRecord is a log written as json or yaml, see WriteParsedLog and RenderJSON
+type LogRecord struct {
+	Hours  []HourRecord `json:"hours" yaml:"hours"`
+	Errors []ParseError `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// HourRecord is a protection hour of a LogRecord, the times are nil when they are not known
+type HourRecord struct {
+	Hour      int            `json:"hour" yaml:"hour"`
+	LocalTime *time.Time     `json:"local_time,omitempty" yaml:"local_time,omitempty"`
+	DomTime   *time.Time     `json:"domtime,omitempty" yaml:"domtime,omitempty"`
+	Actions   []ActionResult `json:"actions" yaml:"actions"`
+}
+
+// ParseError is a line of the log that can't be parsed
+type ParseError struct {
+	Line    int    `json:"line" yaml:"line"`
+	Text    string `json:"text" yaml:"text"`
+	Message string `json:"message" yaml:"message"`
+	Err     error  `json:"-" yaml:"-"`
 }
 
-func NewLogCmd(path, resultPath string) *LogCmd {
-	cmd := &LogCmd{
-		logPath:     path,
-		resultPath:  resultPath,
-		currentHour: 0,
-		lineNumber:  0,
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v: %s", e.Line, e.Err, e.Text)
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Results returns the actions by hour, keys are protection hours starting from 0
+func (l *ParsedLog) Results() map[int][]ActionResult {
+	results := map[int][]ActionResult{}
+	for _, hour := range l.Hours {
+		for _, event := range hour.Events {
+			results[hour.Hour-1] = append(results[hour.Hour-1], EventResults(event)...)
+		}
+	}
+
+	return results
+}
+
+// Record returns the log as it's written by parse_log
+func (l *ParsedLog) Record() *LogRecord {
+	return &LogRecord{Hours: HourRecords(l.Hours), Errors: l.Errors}
+}
+
+// Err joins the errors of the log, it's nil when every line is parsed
+func (l *ParsedLog) Err() error {
+	errs := make([]error, 0, len(l.Errors))
+	for _, err := range l.Errors {
+		errs = append(errs, err)
 	}
-	cmd.loadFile()
-	cmd.initActions()
 
-	return cmd
+	return errors.Join(errs...)
 }
 
-func (c *LogCmd) initActions() {
+// ParseLog reads an import log. Lines that can't be parsed are collected in the
+// errors of the log and reading goes on, the returned error joins them. Lines
+// without an action are errors too, only blank lines are skipped. Actions after an
+// hour line with an error are dropped until the next valid hour so they don't end
+// up in the previous one.
+func ParseLog(r io.Reader) (*ParsedLog, error) {
+	p := &logParser{log: &ParsedLog{Hours: []*HourLog{}}}
+	p.initActions()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.lineNumber++
+		p.currentText = strings.TrimSpace(scanner.Text())
+		if p.currentText == "" {
+			continue
+		}
+
+		p.executeActions()
+	}
+
+	if err := scanner.Err(); err != nil {
+		return p.log, WrapError(err, "error reading log")
+	}
+
+	return p.log, p.log.Err()
+}
+
+// logParser holds the state of ParseLog, every action checks the current line and
+// sets matched when it's the line of the action. invalidHour is set by an hour line
+// with an error until the next valid one.
+type logParser struct {
+	log         *ParsedLog
+	currentText string
+	lineNumber  int
+	matched     bool
+	invalidHour bool
+	actions     []ParseLogFunc
+}
+
+func (c *logParser) initActions() {
 	c.actions = []ParseLogFunc{
 		c.tickAction,
 		c.draftrateAction,
 		c.releaseUnitAction,
+		c.castSpellAction,
+		c.unlockTechAction,
+		c.dailyBonusAction,
+		c.tradeAction,
+		c.exploreAction,
+		c.destructionAction,
+		c.rezoneAction,
+		c.constructionAction,
+		c.trainAction,
+		c.investAction,
+	}
+}
+
+func (c *logParser) executeActions() {
+	c.matched = false
+	for _, actionFunc := range c.actions {
+		if err := actionFunc(); err != nil {
+			c.addError(err)
+			return
+		}
+	}
+
+	if !c.matched {
+		c.addError(errUnknownLine)
+	}
+}
+
+func (c *logParser) addError(err error) {
+	c.log.Errors = append(c.log.Errors, ParseError{
+		Line:    c.lineNumber,
+		Text:    c.currentText,
+		Message: err.Error(),
+		Err:     err,
+	})
+}
+
+// LogCmd parses an import log file and writes its actions as json, yaml or csv
+type LogCmd struct {
+	logPath    string
+	resultPath string
+	format     string
+	parsed     *ParsedLog
+}
+
+func NewLogCmd(path, resultPath, format string) (*LogCmd, error) {
+	if format == "" {
+		format = FormatJSON
+	}
+	if err := checkFormat(format, FormatJSON, FormatYAML, FormatCSV); err != nil {
+		return nil, err
+	}
+
+	return &LogCmd{
+		logPath:    path,
+		resultPath: resultPath,
+		format:     format,
+	}, nil
+}
+
+// Execute writes the parsed actions, lines that can't be parsed are reported
+// after the result is written
+func (c *LogCmd) Execute() error {
+	parseErr := c.Parse()
+	if c.parsed == nil {
+		return parseErr
+	}
+
+	var buf bytes.Buffer
+	if err := WriteParsedLog(&buf, c.parsed, c.format); err != nil {
+		return err
+	}
+
+	if err := writeResult(c.resultPath, buf.Bytes()); err != nil {
+		return err
 	}
+
+	return parseErr
 }
 
-func (c *LogCmd) loadFile() {
+// Parse reads the log file, actions of the lines that are parsed are kept on errors
+func (c *LogCmd) Parse() error {
 	file, err := os.Open(c.logPath)
 	if err != nil {
-		fmt.Printf("Error on reading log file %v\n", err)
-		return
+		return WrapError(err, "error reading log file")
 	}
+	defer file.Close()
 
-	c.file = file
-	c.scanner = bufio.NewScanner(file)
+	c.parsed, err = ParseLog(file)
+	return err
 }
 
-func (c *LogCmd) Execute() {
-	defer c.file.Close()
+// Results returns parsed actions, keys are protection hours starting from 0
+func (c *LogCmd) Results() map[int][]ActionResult {
+	if c.parsed == nil {
+		return map[int][]ActionResult{}
+	}
 
-	c.actionResults = make(map[int][]ActionResult)
+	return c.parsed.Results()
+}
 
-	fmt.Println("Parsing...")
+func (c *logParser) tickAction() error {
+	matches := hourPattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
+	c.matched = true
+	c.invalidHour = true
 
-	for c.scanner.Scan() {
-		if err := c.scanner.Err(); err != nil {
-			fmt.Printf("Error scanning file: %v", err)
-			return
+	hour, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return fmt.Errorf("error parsing hour: %v", err)
+	}
+
+	if hour < 1 || hour > LastHour {
+		return fmt.Errorf("hour %d is out of protection", hour)
+	}
+	if last := len(c.log.Hours); last > 0 && hour <= c.log.Hours[last-1].Hour {
+		return fmt.Errorf("hour %d duplicate or out of order", hour)
+	}
+
+	parsed := &HourLog{Hour: hour, Events: []Event{}}
+	if times := hourTimesPattern.FindStringSubmatch(c.currentText); len(times) > 0 {
+		if parsed.LocalTime, err = time.Parse(hourTimeLayout, times[1]); err != nil {
+			return fmt.Errorf("error parsing local time: %v", err)
+		}
+		if parsed.DomTime, err = time.Parse(hourTimeLayout, times[2]); err != nil {
+			return fmt.Errorf("error parsing dom time: %v", err)
 		}
+	}
 
-		c.currentText = strings.TrimSpace(c.scanner.Text())
-		c.lineNumber++
+	c.log.Hours = append(c.log.Hours, parsed)
+	c.invalidHour = false
 
-		debugLog("Current Line => ", c.currentText)
+	return nil
+}
 
-		if c.currentText == "" {
-			continue
-		}
+func (c *logParser) addEvent(event Event) error {
+	c.matched = true
+	if c.invalidHour {
+		return nil
+	}
 
-		c.executeActions()
+	last := len(c.log.Hours) - 1
+	if last < 0 {
+		return fmt.Errorf("action before the first protection hour")
+	}
 
-		if c.currentHour > 73 {
-			break
-		}
+	c.log.Hours[last].Events = append(c.log.Hours[last].Events, event)
+
+	return nil
+}
 
+func (c *logParser) draftrateAction() error {
+	matches := draftratePattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	if _, err := strconv.Atoi(matches[1]); err != nil {
+		return fmt.Errorf("error parsing draftrate: %v", err)
 	}
+
+	return c.addEvent(DraftRateChanged{Rate: matches[1] + "%"})
 }
 
-func (c *LogCmd) executeActions() {
-	for _, actionFunc := range c.actions {
-		err := actionFunc()
-		if err != nil {
-			fmt.Printf("Error on executing action: %v: CurrentHour: %v Line %v: %v",
-				c.currentHour, err, c.lineNumber, c.currentText)
+// releaseUnitAction parses released units and draftees, the game releases draftees
+// into the peasantry
+func (c *logParser) releaseUnitAction() error {
+	matches := releasePattern.FindStringSubmatch(c.currentText)
 
-			if cmdVars.debugEnabled {
-				debug.PrintStack()
-			}
-			return
-		}
+	if len(matches) == 0 {
+		return nil
+	}
 
-		data, err := json.MarshalIndent(c.actionResults, "", "  ")
-		if err != nil {
-			fmt.Println("Error marshalling results:", err)
-			return
+	amounts, err := parseAmounts(strings.TrimSuffix(matches[1], " into the peasantry"), ", ")
+	if err != nil {
+		return WrapError(err, "error parsing released units")
+	}
+
+	event := UnitsReleased{}
+	for _, amount := range amounts {
+		if resultKey(amount.Name) == resultKey("draftees") {
+			event.Draftees += amount.Value
+			continue
 		}
-		fmt.Println(string(data))
+		event.Units = append(event.Units, amount)
 	}
+
+	return c.addEvent(event)
 }
 
-func (c *LogCmd) tickAction() error {
-	hourPattern := regexp.MustCompile(`Protection Hour: (\d+)`)
-	matches := hourPattern.FindStringSubmatch(c.currentText)
+func (c *logParser) castSpellAction() error {
+	matches := spellPattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	mana, err := parseNumber(matches[2])
+	if err != nil {
+		return fmt.Errorf("error parsing spell mana: %v", err)
+	}
 
-	debugLog("tickAction", matches)
+	return c.addEvent(SpellCast{Spell: matches[1], Mana: mana})
+}
 
+func (c *logParser) unlockTechAction() error {
+	matches := techPattern.FindStringSubmatch(c.currentText)
 	if len(matches) == 0 {
 		return nil
 	}
 
-	hour, err := strconv.Atoi(matches[1])
+	return c.addEvent(TechUnlocked{Tech: matches[1]})
+}
+
+// dailyBonusAction parses both daily platinum and daily land bonuses
+func (c *logParser) dailyBonusAction() error {
+	matches := dailyBonusPattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	amount, err := parseNumber(matches[1])
 	if err != nil {
-		return fmt.Errorf("error parsing hour: %v", err)
+		return fmt.Errorf("error parsing daily bonus: %v", err)
 	}
 
-	debugLog("TickAction: Parsed Hour", hour)
+	if matches[2] == "platinum" {
+		return c.addEvent(DailyPlatinum{Platinum: amount})
+	}
 
-	if hour <= c.currentHour {
-		return fmt.Errorf("hour %d duplicate or out of order", hour)
+	return c.addEvent(DailyLand{Acres: amount, Land: matches[2]})
+}
+
+func (c *logParser) tradeAction() error {
+	matches := tradePattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
 	}
 
-	c.currentHour = hour - 1
+	traded, err := parseAmounts(matches[1], " and ")
+	if err != nil {
+		return WrapError(err, "error parsing traded resources")
+	}
+	received, err := parseAmounts(matches[2], " and ")
+	if err != nil {
+		return WrapError(err, "error parsing received resources")
+	}
 
-	return nil
+	event := ResourcesTraded{}
+	for _, amount := range traded {
+		event.Resources = append(event.Resources, Amount{Name: amount.Name, Value: -amount.Value})
+	}
+	event.Resources = append(event.Resources, received...)
+
+	return c.addEvent(event)
 }
 
-func (c *LogCmd) addActionResult(result *ActionResult) {
-	_, ok := c.actionResults[c.currentHour]
+func (c *logParser) exploreAction() error {
+	matches := explorePattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
 
-	if !ok {
-		c.actionResults[c.currentHour] = []ActionResult{}
+	event := ExplorationStarted{}
+	err := parseAmountsAndCosts(matches[1], matches[2], "explored lands", &event.Lands, map[string]*int{
+		"platinum": &event.PlatinumCost,
+		"draftees": &event.DrafteeCost,
+	})
+	if err != nil {
+		return err
 	}
 
-	c.actionResults[c.currentHour] = append(c.actionResults[c.currentHour], *result)
+	return c.addEvent(event)
 }
 
-func (c *LogCmd) draftrateAction() error {
-	pattern := regexp.MustCompile(`Draftrate changed to (\d+)%`) // Regexp pattern
-	matches := pattern.FindStringSubmatch(c.currentText)
+func (c *logParser) destructionAction() error {
+	matches := destructionPattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	event := BuildingsDestroyed{}
+	if err := parseAmountsAndCosts(matches[1], "", "destroyed buildings", &event.Buildings, nil); err != nil {
+		return err
+	}
 
-	debugLog("DraftrateAction", pattern, matches)
+	return c.addEvent(event)
+}
 
+func (c *logParser) rezoneAction() error {
+	matches := rezonePattern.FindStringSubmatch(c.currentText)
 	if len(matches) == 0 {
 		return nil
 	}
 
-	rate, err := strconv.Atoi(matches[1])
+	event := Rezoned{}
+	err := parseAmountsAndCosts(matches[2], matches[1], "rezoned lands", &event.Lands, map[string]*int{
+		"platinum": &event.PlatinumCost,
+	})
 	if err != nil {
-		return fmt.Errorf("error parsing draftrate: %v", err)
+		return err
 	}
 
-	debugLog("Draftrate:", rate)
+	return c.addEvent(event)
+}
+
+func (c *logParser) constructionAction() error {
+	matches := constructionPattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
 
-	result := &ActionResult{
-		Type: DRAFTRATE,
-		Data: ActionResultData{"value": rate},
+	event := ConstructionStarted{}
+	err := parseAmountsAndCosts(matches[1], matches[2], "constructed buildings", &event.Buildings, map[string]*int{
+		"platinum": &event.PlatinumCost,
+		"lumber":   &event.LumberCost,
+	})
+	if err != nil {
+		return err
 	}
 
-	debugLog("Result", result)
+	return c.addEvent(event)
+}
+
+func (c *logParser) trainAction() error {
+	matches := trainPattern.FindStringSubmatch(c.currentText)
+	if len(matches) == 0 {
+		return nil
+	}
 
-	c.addActionResult(result)
+	event := UnitsTrained{}
+	err := parseAmountsAndCosts(matches[1], matches[2], "trained units", &event.Units, map[string]*int{
+		"platinum": &event.PlatinumCost,
+		"ore":      &event.OreCost,
+		"draftees": &event.DrafteeCost,
+		"spies":    &event.SpyCost,
+		"wizards":  &event.WizardCost,
+	})
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return c.addEvent(event)
 }
 
-func (c *LogCmd) releaseUnitAction() error {
-	pattern := regexp.MustCompile(`You successfully released ([\w\s,]+)`)
-	matches := pattern.FindStringSubmatch(c.currentText)
+// parseAmountsAndCosts parses a list of amounts and the list of costs of the action,
+// costs are summed into the fields of their names. A line without costs has an empty
+// costsText, a cost without a field is an error.
+func parseAmountsAndCosts(amountsText, costsText, name string, amounts *[]Amount, costFields map[string]*int) error {
+	var err error
+	if *amounts, err = parseAmounts(amountsText, ", "); err != nil {
+		return WrapError(err, "error parsing "+name)
+	}
 
-	debugLog("releaseUnitAction", pattern, matches)
+	if costsText == "" {
+		return nil
+	}
+
+	costs, err := parseCosts(costsText)
+	if err != nil {
+		return WrapError(err, "error parsing costs of "+name)
+	}
+	for _, cost := range costs {
+		field, ok := costFields[strings.ToLower(cost.Name)]
+		if !ok {
+			return fmt.Errorf("unknown cost %q of %s", cost.Name, name)
+		}
+		*field += cost.Value
+	}
 
+	return nil
+}
+
+func (c *logParser) investAction() error {
+	matches := investPattern.FindStringSubmatch(c.currentText)
 	if len(matches) == 0 {
 		return nil
 	}
 
-	releasedText := matches[1]
-	unitPattern := regexp.MustCompile(`(\d+)\s([\w\s]+)`)
-	unitMatches := unitPattern.FindAllStringSubmatch(releasedText, -1)
+	amount, err := parseNumber(matches[1])
+	if err != nil {
+		return fmt.Errorf("error parsing invested amount: %v", err)
+	}
 
-	debugLog("UnitMatches", unitMatches)
+	return c.addEvent(Invested{Amount: amount, Resource: matches[2], Improvement: matches[3]})
+}
 
-	releaseData := make(ActionResultData)
+// parseAmounts parses a list like "10 Homes, 5 Farms", amounts can be negative
+func parseAmounts(text, separator string) ([]Amount, error) {
+	amounts := []Amount{}
 
-	for _, unitMatch := range unitMatches {
-		amount, err := strconv.Atoi(unitMatch[1])
-		if err != nil {
-			return fmt.Errorf("error parsing released unit amount: %w", err)
+	for _, item := range strings.Split(text, separator) {
+		matches := amountPattern.FindStringSubmatch(strings.TrimSpace(item))
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("can't parse amount %q", item)
 		}
-		name := strings.TrimSuffix(unitMatch[2], " into the peasantry")
 
-		if mappedName, ok := valuesMap[name]; ok {
-			name = mappedName
+		value, err := parseNumber(matches[1])
+		if err != nil {
+			return nil, err
 		}
 
-		var resultKey string
-		if strings.HasPrefix(name, "military_") {
-			resultKey = strings.TrimPrefix(name, "military_")
-		} else {
-			resultKey = name
-		}
+		amounts = append(amounts, Amount{Name: matches[2], Value: value})
+	}
+
+	return amounts, nil
+}
 
-		releaseData[resultKey] = amount
+// parseCosts parses costs like "100 platinum, 0 ore, and 5 spies" or "600 platinum and 25 draftees"
+func parseCosts(text string) ([]Amount, error) {
+	costs := []Amount{}
 
-		result := &ActionResult{
-			Type: RELEASE,
-			Data: releaseData,
+	for _, item := range costsSeparator.Split(text, -1) {
+		cost, err := parseAmounts(item, ", ")
+		if err != nil {
+			return nil, err
 		}
+		costs = append(costs, cost...)
+	}
 
-		debugLog("Result", result)
+	return costs, nil
+}
 
-		c.addActionResult(result)
+// parseNumber parses a number of the log, it can have thousands separators like 30,000
+func parseNumber(text string) (int, error) {
+	return strconv.Atoi(strings.ReplaceAll(text, ",", ""))
+}
 
+// resultKey maps names of the log to the keys of ActionResult data
+func resultKey(name string) string {
+	if mappedName, ok := valuesMap[name]; ok {
+		name = mappedName
 	}
 
-	return nil
+	return strings.TrimPrefix(name, "military_")
 }
@@ -0,0 +1,73 @@
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteParsedLog writes the actions of a parsed log as json, yaml or csv. The csv
+// has a row for every action of an hour and a column for every data key of the log.
+func WriteParsedLog(w io.Writer, parsed *ParsedLog, format string) error {
+	record := parsed.Record()
+
+	switch format {
+	case FormatJSON, "":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(record)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(record); err != nil {
+			return WrapError(err, "error writing yaml")
+		}
+		return encoder.Close()
+	case FormatCSV:
+		return writeParsedLogCSV(w, record)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func writeParsedLogCSV(w io.Writer, record *LogRecord) error {
+	keySet := ActionResultData{}
+	for _, hour := range record.Hours {
+		for _, action := range hour.Actions {
+			for key := range action.Data {
+				keySet[key] = 0
+			}
+		}
+	}
+	keys := sortedKeys(keySet)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"hour", "type", "name"}, keys...)); err != nil {
+		return WrapError(err, "error writing csv")
+	}
+
+	for _, hour := range record.Hours {
+		for _, action := range hour.Actions {
+			row := []string{strconv.Itoa(hour.Hour), action.Type, action.Name}
+			for _, key := range keys {
+				value, ok := action.Data[key]
+				if !ok {
+					row = append(row, "")
+					continue
+				}
+				row = append(row, strconv.Itoa(value))
+			}
+
+			if err := writer.Write(row); err != nil {
+				return WrapError(err, "error writing csv")
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,102 @@
+package sim
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testParsedLogText = `====== Protection Hour: 1 ( Local Time: 6:00:00 PM 5/18/2024 ) ( Domtime: 12:00:00 AM 5/18/2024 ) ======
+Draftrate changed to 90%.
+Your wizards successfully cast Gaia's Watch at a cost of 462 mana.
+
+====== Protection Hour: 3 ( Local Time: 8:00:00 PM 5/18/2024 ) ( Domtime: 2:00:00 AM 5/18/2024 ) ======
+Construction of 5 Homes, 10 Farms started at a cost of 12345 platinum and 678 lumber.
+`
+
+func TestWriteParsedLog(t *testing.T) {
+	parsed, err := ParseLog(strings.NewReader(testParsedLogText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var sb strings.Builder
+	if err := WriteParsedLog(&sb, parsed, FormatCSV); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "hour,type,name,Farms,Homes,cost_lumber,cost_platinum,mana,value\n" +
+		"1,draftrate,,,,,,,90\n" +
+		"1,magic,Gaia's Watch,,,,,462,\n" +
+		"3,construction,,10,5,678,12345,,\n"
+	if sb.String() != expected {
+		t.Errorf("Incorrect csv:\n got %s\nwant %s", sb.String(), expected)
+	}
+
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		sb.Reset()
+		if err := WriteParsedLog(&sb, parsed, format); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var result struct {
+			Hours []struct {
+				Hour    int `json:"hour" yaml:"hour"`
+				Actions []struct {
+					Type string         `json:"type" yaml:"type"`
+					Data map[string]int `json:"data" yaml:"data"`
+				} `json:"actions" yaml:"actions"`
+			} `json:"hours" yaml:"hours"`
+		}
+		if format == FormatJSON {
+			err = json.Unmarshal([]byte(sb.String()), &result)
+		} else {
+			err = yaml.Unmarshal([]byte(sb.String()), &result)
+		}
+		if err != nil {
+			t.Fatalf("Invalid %s: %v", format, err)
+		}
+
+		if len(result.Hours) != 2 || result.Hours[1].Hour != 3 || result.Hours[1].Actions[0].Data["cost_lumber"] != 678 {
+			t.Errorf("Incorrect %s result: %+v", format, result)
+		}
+	}
+}
+
+func TestLogCmdExecute(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "sim.txt")
+	resultPath := filepath.Join(dir, "parsed.yaml")
+
+	if err := os.WriteFile(logPath, []byte(testParsedLogText+"Destruction of many Farms is complete.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewLogCmd(logPath, resultPath, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+
+	logCmd, err := NewLogCmd(logPath, resultPath, FormatYAML)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The result is written with the errors of the log
+	if err := logCmd.Execute(); err == nil || !strings.Contains(err.Error(), "line 7") {
+		t.Errorf("Expected an error on line 7, got %v", err)
+	}
+
+	content, err := os.ReadFile(resultPath)
+	if err != nil {
+		t.Fatalf("Result is not written: %v", err)
+	}
+	for _, text := range []string{"type: construction", "line: 7", "cost_platinum: 12345"} {
+		if !strings.Contains(string(content), text) {
+			t.Errorf("Result should contain %q, got:\n%s", text, content)
+		}
+	}
+}
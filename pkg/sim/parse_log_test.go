@@ -0,0 +1,146 @@
+package sim
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLog(t *testing.T) {
+	events := []Event{
+		DraftRateChanged{Rate: "90%"},
+		UnitsReleased{Units: []Amount{{"Satyr", 3}, {"Spies", 2}}, Draftees: 7},
+		SpellCast{Spell: "Gaia's Watch", Mana: 578},
+		TechUnlocked{Tech: "Treasure Hunt"},
+		DailyPlatinum{Platinum: 4004},
+		ResourcesTraded{Resources: []Amount{{"platinum", -1000}, {"lumber", 500}, {"ore", 0}, {"gems", 0}}},
+		ExplorationStarted{Lands: []Amount{{"Plains", 5}, {"Forest", 20}}, PlatinumCost: 30000, DrafteeCost: 25},
+		DailyLand{Acres: 20, Land: "Forest"},
+		BuildingsDestroyed{Buildings: []Amount{{"Farms", 2}}},
+		Rezoned{Lands: []Amount{{"Plains", -2}, {"Forest", 2}}, PlatinumCost: 600},
+		ConstructionStarted{Buildings: []Amount{{"Homes", 5}, {"Lumber Yards", 10}}, PlatinumCost: 12345, LumberCost: 678},
+		UnitsTrained{Units: []Amount{{"Satyr", 100}, {"Archspies", 5}}, PlatinumCost: 27500, DrafteeCost: 100, SpyCost: 5},
+		Invested{Amount: 5000, Resource: "lumber", Improvement: "walls"},
+	}
+
+	hours := []*HourLog{
+		{Hour: 1, Events: events[:6]},
+		{Hour: 3, Events: events[6:]},
+	}
+
+	parsed, err := ParseLog(strings.NewReader(RenderText(hours)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(parsed.Hours) != 2 || parsed.Hours[0].Hour != 1 || parsed.Hours[1].Hour != 3 {
+		t.Fatalf("Hours should be in the order of the log: %+v", parsed.Hours)
+	}
+
+	if event := parsed.Hours[0].Events[0]; event != (DraftRateChanged{Rate: "90%"}) {
+		t.Errorf("Expected a draft rate change, got %#v", event)
+	}
+	if event := parsed.Hours[1].Events[0]; !reflect.DeepEqual(event, events[6]) {
+		t.Errorf("Incorrect exploration:\n got %#v\nwant %#v", event, events[6])
+	}
+
+	expected := map[int][]ActionResult{}
+	for _, hour := range hours {
+		for _, event := range hour.Events {
+			expected[hour.Hour-1] = append(expected[hour.Hour-1], EventResults(event)...)
+		}
+	}
+
+	if !reflect.DeepEqual(parsed.Results(), expected) {
+		t.Errorf("Incorrect results:\n got %v\nwant %v", parsed.Results(), expected)
+	}
+}
+
+func TestParseLogThousands(t *testing.T) {
+	text := strings.Join([]string{
+		"====== Protection Hour: 4 ( Local Time: 9:00:00 PM 5/18/2024 ) ( Domtime: 3:00:00 AM 5/18/2024 ) ======",
+		"Your wizards successfully cast Gaia's Watch at a cost of 1,155 mana.",
+		"Exploration for 12 Plains begun at a cost of 31,234 platinum and 12 draftees.",
+		"Training of 1,000 Satyr begun at a cost of 275,000 platinum, 0 ore, 1,000 draftees, 0 spies, and 0 wizards.",
+		"You invested 10,000 platinum into keep.",
+	}, "\n")
+
+	parsed, err := ParseLog(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[int][]ActionResult{
+		3: {
+			{Type: MAGIC, Name: "Gaia's Watch", Data: ActionResultData{"mana": 1155}},
+			{Type: EXPLORE, Data: ActionResultData{"Plains": 12, "cost_platinum": 31234, "cost_draftees": 12}},
+			{Type: TRAIN, Data: ActionResultData{
+				"Satyr": 1000, "cost_platinum": 275000, "cost_ore": 0, "cost_draftees": 1000, "cost_spies": 0, "cost_wizards": 0,
+			}},
+			{Type: INVEST, Name: "keep", Data: ActionResultData{"platinum": 10000}},
+		},
+	}
+
+	if !reflect.DeepEqual(parsed.Results(), expected) {
+		t.Errorf("Incorrect results:\n got %v\nwant %v", parsed.Results(), expected)
+	}
+}
+
+func TestParseLogErrors(t *testing.T) {
+	timeline := renderTimeline(&HourLog{Hour: 2, LocalTime: time.Now(), DomTime: time.Now()})
+
+	testCases := []struct {
+		name string
+		text string
+		line int
+	}{
+		{"hours out of order", timeline + "Draftrate changed to 35%.\n" + strings.Replace(timeline, "Hour: 2", "Hour: 1", 1), 3},
+		{"hour out of protection", strings.Replace(timeline, "Hour: 2", "Hour: 74", 1), 1},
+		{"action before the first hour", "Draftrate changed to 35%.\n" + timeline, 1},
+		{"bad amount", timeline + "Destruction of many Farms is complete.\n", 2},
+		{"bad cost", timeline + "Construction of 5 Homes started at a cost of some platinum and 678 lumber.\n", 2},
+		{"unknown line", timeline + "Your dominion is under protection.\n", 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := ParseLog(strings.NewReader(tc.text))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if len(parsed.Errors) != 1 || parsed.Errors[0].Line != tc.line {
+				t.Errorf("Expected an error on line %d, got %v", tc.line, parsed.Errors)
+			}
+		})
+	}
+
+	// Parsing goes on after a line with an error
+	parsed, err := ParseLog(strings.NewReader(timeline + "Destruction of many Farms is complete.\nDraftrate changed to 35%.\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+	if actions := parsed.Results()[1]; len(actions) != 1 || actions[0].Type != DRAFTRATE {
+		t.Errorf("Actions after the error should be parsed, got %v", actions)
+	}
+
+	// Actions of an out of order hour are not added to the previous hour
+	parsed, _ = ParseLog(strings.NewReader(timeline + "Draftrate changed to 35%.\n" +
+		strings.Replace(timeline, "Hour: 2", "Hour: 1", 1) + "Draftrate changed to 50%.\n" +
+		strings.Replace(timeline, "Hour: 2", "Hour: 3", 1) + "Draftrate changed to 40%.\n"))
+	expected := map[int][]ActionResult{
+		1: {{Type: DRAFTRATE, Data: ActionResultData{"value": 35}}},
+		2: {{Type: DRAFTRATE, Data: ActionResultData{"value": 40}}},
+	}
+	if !reflect.DeepEqual(parsed.Results(), expected) {
+		t.Errorf("Incorrect results after an invalid hour:\n got %v\nwant %v", parsed.Results(), expected)
+	}
+
+	logCmd, err := NewLogCmd("missing.txt", "", FormatJSON)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := logCmd.Parse(); err == nil {
+		t.Error("Expected an error for a missing log file")
+	}
+}
@@ -0,0 +1,191 @@
+package sim
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Plan is a build saved in a TOML file, every protection hour lists its actions
+// by the names of lands, buildings, units and spells so it fits any sim version.
+//
+//	[hours.1]
+//	draftrate = 90
+//	cast = ["Gaia's Watch", "Racial Spell"]
+//	explore = { Forest = 40 }
+//	construct = { Homes = 10, "Lumber Yards" = 5 }
+//	train = { Satyr = 100 }
+//	invest = { walls = { lumber = 5000 } }
+//	trade = { platinum = -1000, lumber = 500 }
+type Plan struct {
+	Hours map[string]PlanHour `toml:"hours"`
+}
+
+// PlanHour is the actions of a protection hour, without a draft rate the previous one is kept
+type PlanHour struct {
+	DraftRate     *int                      `toml:"draftrate"`
+	Release       map[string]int            `toml:"release"`
+	Cast          []string                  `toml:"cast"`
+	Tech          string                    `toml:"tech"`
+	DailyPlatinum bool                      `toml:"daily_platinum"`
+	DailyLand     bool                      `toml:"daily_land"`
+	Trade         map[string]int            `toml:"trade"`
+	Explore       map[string]int            `toml:"explore"`
+	Destroy       map[string]int            `toml:"destroy"`
+	Rezone        map[string]int            `toml:"rezone"`
+	Construct     map[string]int            `toml:"construct"`
+	Train         map[string]int            `toml:"train"`
+	Invest        map[string]map[string]int `toml:"invest"`
+}
+
+// LoadPlan reads a plan file
+func LoadPlan(path string) (*Plan, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, WrapError(err, "error reading plan file")
+	}
+
+	return ParsePlan(content)
+}
+
+func ParsePlan(content []byte) (*Plan, error) {
+	plan := &Plan{}
+
+	meta, err := toml.Decode(string(content), plan)
+	if err != nil {
+		return nil, WrapError(err, "error parsing plan")
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return nil, fmt.Errorf("invalid plan: unknown actions %s", strings.Join(keys, ", "))
+	}
+
+	if err := plan.validate(); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (p *Plan) validate() error {
+	for key, hour := range p.Hours {
+		hr, err := strconv.Atoi(key)
+		if err != nil || hr < 1 || hr > LastHour {
+			return fmt.Errorf("invalid plan: hour %q must be between 1 and %d", key, LastHour)
+		}
+
+		// The same bound as the draftrate rule, a plan must not fill a sim that fails validation
+		if hour.DraftRate != nil && (*hour.DraftRate < 0 || *hour.DraftRate > MaxDraftRate) {
+			return fmt.Errorf("invalid plan: hour %d draft rate must be between 0 and %d", hr, MaxDraftRate)
+		}
+	}
+
+	return nil
+}
+
+// Results converts the plan to actions the same as parsed from an import log,
+// keys are protection hours starting from 0
+func (p *Plan) Results() map[int][]ActionResult {
+	results := map[int][]ActionResult{}
+
+	for key, hour := range p.Hours {
+		hr, _ := strconv.Atoi(key)
+		results[hr-1] = hour.results()
+	}
+
+	return results
+}
+
+func (h PlanHour) results() []ActionResult {
+	results := []ActionResult{}
+	addAmounts := func(actionType string, amounts map[string]int) {
+		if len(amounts) > 0 {
+			data := ActionResultData{}
+			for name, value := range amounts {
+				data[resultKey(name)] += value
+			}
+			results = append(results, ActionResult{Type: actionType, Data: data})
+		}
+	}
+
+	if h.DraftRate != nil {
+		results = append(results, ActionResult{Type: DRAFTRATE, Data: ActionResultData{"value": *h.DraftRate}})
+	}
+	addAmounts(RELEASE, h.Release)
+	for _, spell := range h.Cast {
+		results = append(results, ActionResult{Type: MAGIC, Name: spell, Data: ActionResultData{}})
+	}
+	if h.Tech != "" {
+		results = append(results, ActionResult{Type: TECH, Name: h.Tech, Data: ActionResultData{}})
+	}
+	if h.DailyPlatinum {
+		results = append(results, ActionResult{Type: DAILY, Data: ActionResultData{"platinum": 0}})
+	}
+	if h.DailyLand {
+		results = append(results, ActionResult{Type: DAILY, Data: ActionResultData{"land": LandBonus}})
+	}
+	addAmounts(BANK, h.Trade)
+	addAmounts(EXPLORE, h.Explore)
+	addAmounts(DESTRUCTION, h.Destroy)
+	addAmounts(REZONE, h.Rezone)
+	addAmounts(CONSTRUCTION, h.Construct)
+	addAmounts(TRAIN, h.Train)
+
+	improvements := make([]string, 0, len(h.Invest))
+	for improvement := range h.Invest {
+		improvements = append(improvements, improvement)
+	}
+	sort.Strings(improvements)
+	for _, improvement := range improvements {
+		results = append(results, ActionResult{Type: INVEST, Name: improvement, Data: ActionResultData(h.Invest[improvement])})
+	}
+
+	return results
+}
+
+// ApplyPlanCmd fills the input cells of a sim with the actions of a plan
+type ApplyPlanCmd struct {
+	planPath string
+	simPath  string
+	outPath  string
+	clear    bool
+	layout   *Layout
+	options  GameLogOptions
+}
+
+func NewApplyPlanCmd(planPath, simPath, outPath string, clear bool, options GameLogOptions) (*ApplyPlanCmd, error) {
+	if err := checkOutPath(simPath, outPath); err != nil {
+		return nil, err
+	}
+
+	layout, err := LoadLayout(options.LayoutPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApplyPlanCmd{
+		planPath: planPath,
+		simPath:  simPath,
+		outPath:  outPath,
+		clear:    clear,
+		layout:   layout,
+		options:  options,
+	}, nil
+}
+
+func (c *ApplyPlanCmd) Execute() error {
+	plan, err := LoadPlan(c.planPath)
+	if err != nil {
+		return err
+	}
+
+	return fillSim(c.simPath, c.outPath, c.layout, c.options, c.clear, plan.Results())
+}
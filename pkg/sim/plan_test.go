@@ -0,0 +1,105 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlan(t *testing.T) {
+	content := `
+[hours.1]
+draftrate = 90
+cast = ["Gaia's Watch", "Racial Spell"]
+daily_platinum = true
+trade = { platinum = -1000, lumber = 500 }
+
+[hours.3]
+draftrate = 0
+explore = { Forest = 40 }
+construct = { Homes = 10, "Lumber Yards" = 5 }
+train = { Satyr = 100 }
+release = { draftees = 7 }
+tech = "Treasure Hunt"
+daily_land = true
+invest = { walls = { lumber = 5000 }, keep = { platinum = 1000 } }
+`
+
+	plan, err := ParsePlan([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[int][]ActionResult{
+		0: {
+			{Type: DRAFTRATE, Data: ActionResultData{"value": 90}},
+			{Type: MAGIC, Name: "Gaia's Watch", Data: ActionResultData{}},
+			{Type: MAGIC, Name: "Racial Spell", Data: ActionResultData{}},
+			{Type: DAILY, Data: ActionResultData{"platinum": 0}},
+			{Type: BANK, Data: ActionResultData{"platinum": -1000, "lumber": 500}},
+		},
+		2: {
+			{Type: DRAFTRATE, Data: ActionResultData{"value": 0}},
+			{Type: RELEASE, Data: ActionResultData{"draftees": 7}},
+			{Type: TECH, Name: "Treasure Hunt", Data: ActionResultData{}},
+			{Type: DAILY, Data: ActionResultData{"land": LandBonus}},
+			{Type: EXPLORE, Data: ActionResultData{"Forest": 40}},
+			{Type: CONSTRUCTION, Data: ActionResultData{"Homes": 10, "lumberyard": 5}},
+			{Type: TRAIN, Data: ActionResultData{"Satyr": 100}},
+			{Type: INVEST, Name: "keep", Data: ActionResultData{"platinum": 1000}},
+			{Type: INVEST, Name: "walls", Data: ActionResultData{"lumber": 5000}},
+		},
+	}
+
+	if results := plan.Results(); !reflect.DeepEqual(results, expected) {
+		t.Errorf("Incorrect results:\n got %v\nwant %v", results, expected)
+	}
+}
+
+func TestParsePlanErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"invalid toml", "[hours.1\n"},
+		{"unknown action", "[hours.1]\nbuild = { Homes = 10 }\n"},
+		{"hour out of protection", "[hours.74]\ndraftrate = 90\n"},
+		{"hour is not a number", "[hours.first]\ndraftrate = 90\n"},
+		{"draft rate over 100", "[hours.1]\ndraftrate = 120\n"},
+		{"draft rate over the maximum", "[hours.1]\ndraftrate = 91\n"},
+		{"negative draft rate", "[hours.1]\ndraftrate = -1\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParsePlan([]byte(tc.content)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestApplyPlan(t *testing.T) {
+	workbook := newLog2SimWorkbook(t)
+	defer workbook.Close()
+
+	plan, err := ParsePlan([]byte("[hours.3]\ntrain = { Satyr = 100, Archspies = 5 }\ncast = [\"Mechanical Genius\"]\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := WriteLogToSim(workbook, mustDefaultLayout(), plan.Results())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for ref, want := range map[string]string{"Military!AG6": "100", "Military!AL6": "5", "Magic!L6": "1"} {
+		sheet, cell := splitRef(ref)
+		if value, _ := workbook.GetCellValue(sheet, cell); value != want {
+			t.Errorf("%s should be %q, got %q", ref, want, value)
+		}
+	}
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", result.Warnings)
+	}
+}
@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/rxx/od_tools/data"
+	"gopkg.in/yaml.v3"
+)
+
+// RaceData is a race of the game from data/races
+type RaceData struct {
+	Key          string `yaml:"key"`
+	Name         string `yaml:"name"`
+	HomeLandType string `yaml:"home_land_type"`
+}
+
+// LoadRaces reads the races of the game
+func LoadRaces() ([]RaceData, error) {
+	files, err := fs.Glob(data.FS, "races/*.yml")
+	if err != nil {
+		return nil, WrapError(err, "error listing races")
+	}
+	sort.Strings(files)
+
+	races := []RaceData{}
+	for _, file := range files {
+		content, err := data.FS.ReadFile(file)
+		if err != nil {
+			return nil, WrapError(err, "error reading race "+file)
+		}
+
+		race := RaceData{}
+		if err := yaml.Unmarshal(content, &race); err != nil {
+			return nil, WrapError(err, "error parsing race "+file)
+		}
+		races = append(races, race)
+	}
+
+	return races, nil
+}
+
+// raceKey finds the key of a race by its name or key, races without data are
+// named by their spell data keys like "dark-elf"
+func raceKey(races []RaceData, name string) string {
+	name = strings.TrimSpace(name)
+	for _, race := range races {
+		if strings.EqualFold(race.Name, name) || strings.EqualFold(race.Key, name) {
+			return race.Key
+		}
+	}
+
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+}
+
+// FindRacialSpell returns the active self spell of the race, a race without one
+// takes the spell of its rework like nomad-rework
+func FindRacialSpell(spells map[string]SpellData, races []RaceData, race string) (SpellData, error) {
+	key := raceKey(races, race)
+
+	for _, raceKey := range []string{key, key + "-rework"} {
+		found := []SpellData{}
+		for _, spell := range spells {
+			if spell.Category != "self" || (spell.Active != nil && !*spell.Active) {
+				continue
+			}
+			if containsString(spell.Races, raceKey) {
+				found = append(found, spell)
+			}
+		}
+
+		if len(found) > 0 {
+			sort.Slice(found, func(i, j int) bool { return found[i].Key < found[j].Key })
+			return found[0], nil
+		}
+	}
+
+	return SpellData{}, fmt.Errorf("unknown race %q, it has no racial spell in %s", race, spellsPath)
+}
@@ -0,0 +1,36 @@
+package sim
+
+import "testing"
+
+func TestFindRacialSpell(t *testing.T) {
+	spells, err := LoadSpells()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	races, err := LoadRaces()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for race, expected := range map[string]string{
+		"Sylvan":   "Verdant Bloom",
+		"sylvan":   "Verdant Bloom",
+		"Dark Elf": "Delve into Shadow",
+		"Nomad":    "Favorable Terrain",
+		"Undead":   "Parasitic Hunger",
+		"Gnome":    "Miner's Sight",
+	} {
+		spell, err := FindRacialSpell(spells, races, race)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", race, err)
+			continue
+		}
+		if spell.Name != expected {
+			t.Errorf("Racial spell of %s should be %q, got %q", race, expected, spell.Name)
+		}
+	}
+
+	if _, err := FindRacialSpell(spells, races, "Pixie"); err == nil {
+		t.Error("Expected an error for an unknown race")
+	}
+}
@@ -0,0 +1,194 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Calculator is a sim able to evaluate its formulas, like an excelize workbook
+type Calculator interface {
+	Sim
+	GetCellFormula(sheet, cell string) (string, error)
+	CalcCellValue(sheet, cell string, opts ...excelize.Options) (string, error)
+	GetCellStyle(sheet, cell string) (int, error)
+	GetStyle(idx int) (*excelize.Style, error)
+}
+
+// Cell of the scratch workbook calculated numbers are formatted in
+const (
+	formatSheet = "Sheet1"
+	formatCell  = "A1"
+)
+
+var unsupportedFuncRegex = regexp.MustCompile(`not support (\S+) function`)
+
+var formulaErrors = []string{"#NULL!", "#DIV/0!", "#VALUE!", "#REF!", "#NAME?", "#NUM!", "#N/A"}
+
+// RecalcSim recalculates formula cells when they are read instead of returning
+// the values cached by the last save in Excel. Cells it can't evaluate fall back
+// to the cached value and are listed by Problems.
+//
+// CalcCellValue of excelize doesn't format numbers below 1 (0.75 of an h:mm cell,
+// 0.35 of a percent cell), so calculated numbers are formatted with the number
+// format of their cell like the cached values are.
+type RecalcSim struct {
+	Calculator
+	values   map[string]string
+	problems map[string][]string // problem => cells
+	scratch  *excelize.File
+	styles   map[int]int // sim style => scratch style with the same number format
+}
+
+func NewRecalcSim(sim Calculator) *RecalcSim {
+	return &RecalcSim{
+		Calculator: sim,
+		values:     map[string]string{},
+		problems:   map[string][]string{},
+		styles:     map[int]int{},
+	}
+}
+
+func (s *RecalcSim) GetCellValue(sheet, cell string, opts ...excelize.Options) (string, error) {
+	ref, key := sheet+"!"+cell, sheet+"!"+cell
+	if rawCellValue(opts) {
+		key += " raw"
+	}
+	if value, ok := s.values[key]; ok {
+		return value, nil
+	}
+
+	formula, err := s.Calculator.GetCellFormula(sheet, cell)
+	if err != nil {
+		return "", err
+	}
+	if formula == "" {
+		return s.Calculator.GetCellValue(sheet, cell, opts...)
+	}
+
+	cached, err := s.Calculator.GetCellValue(sheet, cell, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := s.Calculator.CalcCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+	if problem := recalcProblem(value, cached, err); problem != "" {
+		s.problems[problem] = append(s.problems[problem], ref)
+		value = cached
+	} else if !rawCellValue(opts) {
+		if value, err = s.formatValue(sheet, cell, value); err != nil {
+			return "", WrapError(err, "error formatting "+ref)
+		}
+	}
+
+	s.values[key] = value
+	return value, nil
+}
+
+// formatValue formats a calculated number with the number format of the cell,
+// excelize only formats numbers of a cell it has the value of
+func (s *RecalcSim) formatValue(sheet, cell, value string) (string, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value, nil
+	}
+
+	styleID, err := s.Calculator.GetCellStyle(sheet, cell)
+	if err != nil || styleID == 0 {
+		return value, err
+	}
+
+	if s.scratch == nil {
+		s.scratch = excelize.NewFile()
+	}
+
+	scratchID, ok := s.styles[styleID]
+	if !ok {
+		style, err := s.Calculator.GetStyle(styleID)
+		if err != nil {
+			return value, err
+		}
+		if scratchID, err = s.scratch.NewStyle(&excelize.Style{NumFmt: style.NumFmt, CustomNumFmt: style.CustomNumFmt}); err != nil {
+			return value, err
+		}
+		s.styles[styleID] = scratchID
+	}
+
+	if err := s.scratch.SetCellFloat(formatSheet, formatCell, number, -1, 64); err != nil {
+		return value, err
+	}
+	if err := s.scratch.SetCellStyle(formatSheet, formatCell, formatCell, scratchID); err != nil {
+		return value, err
+	}
+
+	return s.scratch.GetCellValue(formatSheet, formatCell)
+}
+
+// Close closes the sim and the scratch workbook of formatted values
+func (s *RecalcSim) Close() error {
+	if s.scratch != nil {
+		s.scratch.Close()
+	}
+	return s.Calculator.Close()
+}
+
+func rawCellValue(opts []excelize.Options) bool {
+	for _, opt := range opts {
+		if opt.RawCellValue {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSheetList keeps the version check working through the decorator
+func (s *RecalcSim) GetSheetList() []string {
+	if lister, ok := s.Calculator.(SheetLister); ok {
+		return lister.GetSheetList()
+	}
+	return nil
+}
+
+// recalcProblem describes why a recalculated value can't be used, empty when it can
+func recalcProblem(value, cached string, err error) string {
+	if err != nil {
+		if match := unsupportedFuncRegex.FindStringSubmatch(err.Error()); match != nil {
+			return fmt.Sprintf("function %s is not supported", match[1])
+		}
+		return err.Error()
+	}
+
+	// Errors already cached by Excel are the sim's own, not recalculation ones
+	if containsString(formulaErrors, value) && value != cached {
+		return "formula evaluates to " + value
+	}
+
+	return ""
+}
+
+// Problems returns recalculation problems with their cells in a stable order
+func (s *RecalcSim) Problems() []string {
+	problems := make([]string, 0, len(s.problems))
+	for problem := range s.problems {
+		problems = append(problems, problem)
+	}
+	sort.Strings(problems)
+
+	for i, problem := range problems {
+		problems[i] = fmt.Sprintf("%s, cached values are used in %s", problem, strings.Join(s.problems[problem], ", "))
+	}
+
+	return problems
+}
+
+// PrintProblems writes a report of cells which were not recalculated
+func (s *RecalcSim) PrintProblems(w io.Writer) {
+	for _, problem := range s.Problems() {
+		fmt.Fprintln(w, "recalc:", problem)
+	}
+}
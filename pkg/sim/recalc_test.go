@@ -0,0 +1,125 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRecalcSim(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Sheet1"
+	file.SetCellValue(sheet, "A1", 10)
+	file.SetCellValue(sheet, "A2", 5)
+	file.SetCellFormula(sheet, "B1", "A1*2")
+	file.SetCellFormula(sheet, "B2", "SUM(A1:A2)")
+	file.SetCellFormula(sheet, "B3", "NOSUCHFUNC(A1)")
+
+	// Values saved by Excel before the inputs were changed by a script
+	file.SetCellValue(sheet, "A1", 20)
+	setCachedValue(t, file, sheet, "B1", 20)
+	setCachedValue(t, file, sheet, "B3", 7)
+
+	recalc := NewRecalcSim(file)
+
+	testCases := []struct {
+		cell     string
+		expected string
+	}{
+		{"A1", "20"},
+		{"B1", "40"},
+		{"B2", "25"},
+		{"B3", "7"},
+	}
+
+	for _, tc := range testCases {
+		value, err := recalc.GetCellValue(sheet, tc.cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value != tc.expected {
+			t.Errorf("Incorrect %s value: got %q, want %q", tc.cell, value, tc.expected)
+		}
+	}
+
+	problems := recalc.Problems()
+	if len(problems) != 1 {
+		t.Fatalf("Expected one problem, got %v", problems)
+	}
+	if !strings.Contains(problems[0], "NOSUCHFUNC") || !strings.Contains(problems[0], "Sheet1!B3") {
+		t.Errorf("Incorrect problem: %q", problems[0])
+	}
+}
+
+func TestRecalcSimNumberFormats(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := "Sheet1"
+	file.SetCellValue(sheet, "A1", 18)
+	file.SetCellValue(sheet, "A2", 35)
+	file.SetCellValue(sheet, "A3", 1250)
+	file.SetCellFormula(sheet, "B1", "A1/24")
+	file.SetCellFormula(sheet, "B2", "A2/100")
+	file.SetCellFormula(sheet, "B3", "A3*2")
+	file.SetCellFormula(sheet, "B4", "A2/100")
+
+	// Local Time, draft rate and cost cells of the sim
+	for cell, numFmt := range map[string]int{"B1": 20, "B2": 9, "B3": 3} {
+		style, err := file.NewStyle(&excelize.Style{NumFmt: numFmt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := file.SetCellStyle(sheet, cell, cell, style); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recalc := NewRecalcSim(file)
+	defer recalc.Close()
+
+	testCases := []struct {
+		cell     string
+		expected string
+	}{
+		{"B1", "18:00"},
+		{"B2", "35%"},
+		{"B3", "2,500"},
+		{"B4", "0.35"},
+	}
+
+	for _, tc := range testCases {
+		value, err := recalc.GetCellValue(sheet, tc.cell)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value != tc.expected {
+			t.Errorf("Incorrect %s value: got %q, want %q", tc.cell, value, tc.expected)
+		}
+	}
+
+	if value, _ := recalc.GetCellValue(sheet, "B2", excelize.Options{RawCellValue: true}); value != "0.35" {
+		t.Errorf("Incorrect raw B2 value: got %q, want %q", value, "0.35")
+	}
+
+	if len(recalc.Problems()) != 0 {
+		t.Errorf("Unexpected problems: %v", recalc.Problems())
+	}
+}
+
+// setCachedValue keeps the formula of the cell and replaces its cached result
+func setCachedValue(t *testing.T, file *excelize.File, sheet, cell string, value int) {
+	formula, err := file.GetCellFormula(sheet, cell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := file.SetCellValue(sheet, cell, value); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.SetCellFormula(sheet, cell, formula); err != nil {
+		t.Fatal(err)
+	}
+}
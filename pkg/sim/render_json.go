@@ -0,0 +1,58 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// HourRecords converts hours with events to the hours of a log record, hours without events are skipped.
+func HourRecords(hours []*HourLog) []HourRecord {
+	records := []HourRecord{}
+
+	for _, hour := range hours {
+		if len(hour.Events) == 0 {
+			continue
+		}
+
+		record := HourRecord{Hour: hour.Hour, Actions: []ActionResult{}}
+		if !hour.LocalTime.IsZero() {
+			localTime := hour.LocalTime
+			record.LocalTime = &localTime
+		}
+		if !hour.DomTime.IsZero() {
+			domTime := hour.DomTime
+			record.DomTime = &domTime
+		}
+		for _, event := range hour.Events {
+			record.Actions = append(record.Actions, EventResults(event)...)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// RenderJSON renders hours as an indented JSON log in the same shape parse_log writes
+func RenderJSON(hours []*HourLog) (string, error) {
+	data, err := json.MarshalIndent(&LogRecord{Hours: HourRecords(hours)}, "", "  ")
+	if err != nil {
+		return "", WrapError(err, "error marshalling hours")
+	}
+
+	return string(data), nil
+}
+
+// RenderNDJSON renders hours as one JSON hour per line
+func RenderNDJSON(hours []*HourLog) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+
+	for _, record := range HourRecords(hours) {
+		if err := encoder.Encode(record); err != nil {
+			return "", WrapError(err, "error marshalling hour")
+		}
+	}
+
+	return buf.String(), nil
+}
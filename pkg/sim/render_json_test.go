@@ -0,0 +1,116 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderJSON(t *testing.T) {
+	localTime := time.Date(2024, 5, 18, 18, 0, 0, 0, time.UTC)
+	hours := []*HourLog{
+		{
+			Hour:      1,
+			LocalTime: localTime,
+			DomTime:   localTime.Add(-18 * time.Hour),
+			Events: []Event{
+				DraftRateChanged{Rate: "90%"},
+				UnitsReleased{Units: []Amount{{"Spies", 5}}, Draftees: 20},
+				SpellCast{Spell: "Gaia's Watch", Mana: 578},
+				ResourcesTraded{Resources: []Amount{{"platinum", -1000}, {"lumber", 500}, {"ore", 0}}},
+				ExplorationStarted{Lands: []Amount{{"Plains", 5}}, PlatinumCost: 30000, DrafteeCost: 25},
+				Invested{Amount: 5000, Resource: "lumber", Improvement: "walls"},
+			},
+		},
+		{Hour: 2, LocalTime: localTime.Add(time.Hour), DomTime: localTime.Add(-17 * time.Hour)},
+	}
+
+	domTime := localTime.Add(-18 * time.Hour)
+	expected := []HourRecord{
+		{
+			Hour:      1,
+			LocalTime: &localTime,
+			DomTime:   &domTime,
+			Actions: []ActionResult{
+				{Type: DRAFTRATE, Data: ActionResultData{"value": 90}},
+				{Type: RELEASE, Data: ActionResultData{"spies": 5}},
+				{Type: RELEASE, Data: ActionResultData{"draftees": 20}},
+				{Type: MAGIC, Name: "Gaia's Watch", Data: ActionResultData{"mana": 578}},
+				{Type: BANK, Data: ActionResultData{"platinum": -1000, "lumber": 500}},
+				{Type: EXPLORE, Data: ActionResultData{"Plains": 5, "cost_platinum": 30000, "cost_draftees": 25}},
+				{Type: INVEST, Name: "walls", Data: ActionResultData{"lumber": 5000}},
+			},
+		},
+	}
+
+	result, err := RenderJSON(hours)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var parsed LogRecord
+	if err := json.Unmarshal([]byte(result), &parsed); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed.Hours, expected) {
+		t.Errorf("Incorrect hours: got %+v, want %+v", parsed.Hours, expected)
+	}
+
+	lines, err := RenderNDJSON(hours)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count := strings.Count(lines, "\n"); count != 1 {
+		t.Errorf("Expected one line per hour with events, got %d", count)
+	}
+}
+
+func TestRenderJSONParsedLog(t *testing.T) {
+	simData := map[string]map[string]string{
+		Overview:     {"B15": "5/18/2024"},
+		Imps:         {},
+		Military:     {"Y4": "35%"},
+		Construction: {"Q5": "10", "AQ5": "8,500", "AR5": "880"},
+		Explore:      {"T6": "20", "AH6": "30,000", "AI6": "25"},
+	}
+	for hr := 1; hr <= LastHour; hr++ {
+		simData[Imps][fmt.Sprintf("BY%d", hr+3)] = fmt.Sprintf("%d:00", (hr+17)%24)
+		simData[Imps][fmt.Sprintf("BZ%d", hr+3)] = fmt.Sprintf("%d:00", (hr-1)%24)
+	}
+
+	glc := newMockGameLog(&SimMock{Data: simData, AllowMissing: true})
+	glc.initActions()
+	glc.options = GameLogOptions{From: 1, To: LastHour}
+
+	hours, err := glc.generateHours()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rendered, err := RenderJSON(hours)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parsed, err := ParseLog(strings.NewReader(RenderText(hours)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteParsedLog(&buf, parsed, FormatJSON); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(parsed.Hours) != 3 || parsed.Hours[0].LocalTime.IsZero() || parsed.Hours[0].LocalTime.Hour() != 18 {
+		t.Fatalf("Expected hours 1 to 3 with times, got %+v", parsed.Hours)
+	}
+
+	if strings.TrimSpace(rendered) != strings.TrimSpace(buf.String()) {
+		t.Errorf("Rendered JSON doesn't match parse_log:\n%s\nparse_log:\n%s", rendered, buf.String())
+	}
+}
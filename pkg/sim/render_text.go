@@ -0,0 +1,158 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderText renders hours in the OpenDominion import log format, hours without
+// events are skipped.
+func RenderText(hours []*HourLog) string {
+	var sb strings.Builder
+
+	for _, hour := range hours {
+		result := RenderHour(hour)
+		if result == "" {
+			continue
+		}
+
+		sb.WriteString(result)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// RenderHour renders the timeline and the events of an hour, empty string if
+// there are no events.
+func RenderHour(hour *HourLog) string {
+	if len(hour.Events) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(renderTimeline(hour))
+	sb.WriteString(renderEvents(hour.Events))
+
+	return sb.String()
+}
+
+// Time and date of the hour line, parse_log reads them back with it
+const hourTimeLayout = "3:04:05 PM 1/2/2006"
+
+func renderTimeline(hour *HourLog) string {
+	localTimeLong := hour.LocalTime.Format("3:04:05 PM")
+	localTimeShort := hour.LocalTime.Format("1/2/2006")
+	domTimeLong := hour.DomTime.Format("3:04:05 PM")
+	domTimeShort := hour.DomTime.Format("1/2/2006")
+
+	var timeline strings.Builder
+	timeline.WriteString("====== Protection Hour: ")
+	timeline.WriteString(fmt.Sprintf("%d", hour.Hour))
+	timeline.WriteString(" ( Local Time: ")
+	timeline.WriteString(localTimeLong)
+	timeline.WriteString(" ")
+	timeline.WriteString(localTimeShort)
+	timeline.WriteString(" ) ( Domtime: ")
+	timeline.WriteString(domTimeLong)
+	timeline.WriteString(" ")
+	timeline.WriteString(domTimeShort)
+	timeline.WriteString(" ) ======\n")
+
+	return timeline.String()
+}
+
+func renderEvents(events []Event) string {
+	var sb strings.Builder
+
+	for _, event := range events {
+		result := renderEvent(event)
+		if result == "" {
+			continue
+		}
+
+		sb.WriteString(result)
+
+		if !strings.HasSuffix(result, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func renderAmounts(amounts []Amount) string {
+	items := make([]string, 0, len(amounts))
+	for _, amount := range amounts {
+		items = append(items, fmt.Sprintf("%d %s", amount.Value, amount.Name))
+	}
+
+	return strings.Join(items, ", ")
+}
+
+func renderEvent(event Event) string {
+	switch e := event.(type) {
+	case DraftRateChanged:
+		return fmt.Sprintf("Draftrate changed to %s.\n", e.Rate)
+	case UnitsReleased:
+		var sb strings.Builder
+		if len(e.Units) > 0 {
+			sb.WriteString(fmt.Sprintf("You successfully released %s.\n", renderAmounts(e.Units)))
+		}
+		if e.Draftees > 0 {
+			sb.WriteString(fmt.Sprintf("You successfully released %d draftees into the peasantry.\n", e.Draftees))
+		}
+		return sb.String()
+	case SpellCast:
+		return fmt.Sprintf("Your wizards successfully cast %s at a cost of %d mana.\n", e.Spell, e.Mana)
+	case TechUnlocked:
+		return fmt.Sprintf("You have unlocked %s.\n", e.Tech)
+	case DailyPlatinum:
+		return fmt.Sprintf("You have been awarded with %d platinum.\n", e.Platinum)
+	case ResourcesTraded:
+		return renderTrade(e)
+	case ExplorationStarted:
+		return fmt.Sprintf("Exploration for %s begun at a cost of %d platinum and %d draftees.\n",
+			renderAmounts(e.Lands), e.PlatinumCost, e.DrafteeCost)
+	case DailyLand:
+		return fmt.Sprintf("You have been awarded with %d %s.\n", e.Acres, e.Land)
+	case BuildingsDestroyed:
+		return fmt.Sprintf("Destruction of %s is complete.\n", renderAmounts(e.Buildings))
+	case Rezoned:
+		return fmt.Sprintf("Rezoning begun at a cost of %d platinum. The changes in land are as following: %s.\n",
+			e.PlatinumCost, renderAmounts(e.Lands))
+	case ConstructionStarted:
+		return fmt.Sprintf("Construction of %s started at a cost of %d platinum and %d lumber.\n",
+			renderAmounts(e.Buildings), e.PlatinumCost, e.LumberCost)
+	case UnitsTrained:
+		return fmt.Sprintf("Training of %s begun at a cost of %d platinum, %d ore, %d draftees, %d spies, and %d wizards.\n",
+			renderAmounts(e.Units), e.PlatinumCost, e.OreCost, e.DrafteeCost, e.SpyCost, e.WizardCost)
+	case Invested:
+		return fmt.Sprintf("You invested %d %s into %s.\n", e.Amount, e.Resource, e.Improvement)
+	}
+
+	return ""
+}
+
+func renderTrade(e ResourcesTraded) string {
+	var sb strings.Builder
+	var tradedItems []string
+	var receivedItems []string
+
+	for _, resource := range e.Resources {
+		if resource.Value < 0 {
+			tradedItems = append(tradedItems, fmt.Sprintf("%d %s", -resource.Value, resource.Name))
+		} else if resource.Value > 0 {
+			receivedItems = append(receivedItems, fmt.Sprintf("%d %s", resource.Value, resource.Name))
+		}
+	}
+
+	if len(tradedItems) > 0 {
+		sb.WriteString(strings.Join(tradedItems, " and ") + " have been traded for ")
+	}
+	if len(receivedItems) > 0 {
+		sb.WriteString(strings.Join(receivedItems, " and ") + ".\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+package sim
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderText(t *testing.T) {
+	hour := &HourLog{
+		Hour:      3,
+		LocalTime: time.Date(2024, 5, 18, 20, 0, 0, 0, time.UTC),
+		DomTime:   time.Date(2024, 5, 18, 2, 0, 0, 0, time.UTC),
+		Events: []Event{
+			DraftRateChanged{Rate: "90%"},
+			UnitsReleased{Units: []Amount{{"Satyr", 3}}, Draftees: 7},
+			SpellCast{Spell: "Gaia's Watch", Mana: 578},
+			TechUnlocked{Tech: "Treasure Hunt"},
+			DailyPlatinum{Platinum: 4004},
+			ResourcesTraded{Resources: []Amount{{"platinum", -1000}, {"lumber", 500}, {"ore", 0}, {"gems", 0}}},
+			ExplorationStarted{Lands: []Amount{{"Plains", 5}, {"Forest", 20}}, PlatinumCost: 30000, DrafteeCost: 25},
+			DailyLand{Acres: 20, Land: "Forest"},
+			BuildingsDestroyed{Buildings: []Amount{{"Farms", 2}}},
+			Rezoned{Lands: []Amount{{"Plains", -2}, {"Forest", 2}}, PlatinumCost: 600},
+			ConstructionStarted{Buildings: []Amount{{"Homes", 5}, {"Farms", 10}}, PlatinumCost: 12345, LumberCost: 678},
+			UnitsTrained{Units: []Amount{{"Satyr", 100}, {"Archspies", 5}}, PlatinumCost: 27500, DrafteeCost: 100, SpyCost: 5},
+			Invested{Amount: 5000, Resource: "lumber", Improvement: "walls"},
+		},
+	}
+
+	expected := "====== Protection Hour: 3 ( Local Time: 8:00:00 PM 5/18/2024 ) ( Domtime: 2:00:00 AM 5/18/2024 ) ======\n" +
+		"Draftrate changed to 90%.\n" +
+		"You successfully released 3 Satyr.\n" +
+		"You successfully released 7 draftees into the peasantry.\n" +
+		"Your wizards successfully cast Gaia's Watch at a cost of 578 mana.\n" +
+		"You have unlocked Treasure Hunt.\n" +
+		"You have been awarded with 4004 platinum.\n" +
+		"1000 platinum have been traded for 500 lumber.\n" +
+		"Exploration for 5 Plains, 20 Forest begun at a cost of 30000 platinum and 25 draftees.\n" +
+		"You have been awarded with 20 Forest.\n" +
+		"Destruction of 2 Farms is complete.\n" +
+		"Rezoning begun at a cost of 600 platinum. The changes in land are as following: -2 Plains, 2 Forest.\n" +
+		"Construction of 5 Homes, 10 Farms started at a cost of 12345 platinum and 678 lumber.\n" +
+		"Training of 100 Satyr, 5 Archspies begun at a cost of 27500 platinum, 0 ore, 100 draftees, 5 spies, and 0 wizards.\n" +
+		"You invested 5000 lumber into walls.\n"
+
+	if result := RenderHour(hour); result != expected {
+		t.Errorf("Incorrect result: got %q, want %q", result, expected)
+	}
+
+	empty := &HourLog{Hour: 4, LocalTime: hour.LocalTime, DomTime: hour.DomTime}
+	if result := RenderText([]*HourLog{hour, empty, hour}); result != expected+"\n"+expected+"\n" {
+		t.Errorf("Empty hours should be skipped: got %q", result)
+	}
+}
+
+func TestRenderTradeEdgeCases(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			name:     "Nothing Received",
+			event:    ResourcesTraded{Resources: []Amount{{"platinum", -1000}}},
+			expected: "1000 platinum have been traded for \n",
+		},
+		{
+			name:     "Several Resources",
+			event:    ResourcesTraded{Resources: []Amount{{"platinum", -1000}, {"lumber", -200}, {"ore", 600}}},
+			expected: "1000 platinum and 200 lumber have been traded for 600 ore.\n",
+		},
+		{
+			name:     "Rezone Without Lands",
+			event:    Rezoned{PlatinumCost: 600},
+			expected: "Rezoning begun at a cost of 600 platinum. The changes in land are as following: .\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := renderEvents([]Event{tc.event}); result != tc.expected {
+				t.Errorf("Incorrect result: got %q, want %q", result, tc.expected)
+			}
+		})
+	}
+}
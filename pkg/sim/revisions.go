@@ -0,0 +1,119 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rxx/od_tools/data"
+	"gopkg.in/yaml.v3"
+)
+
+const revisionsPath = "revisions.yml"
+
+// Revision is a known release of the sim workbook read by the layout revision Layout.
+// Fingerprint is the fingerprint of its key header cells, see LayoutFingerprint,
+// Constants is the hash of its Constants sheet when it's known.
+type Revision struct {
+	Name        string `yaml:"name"`
+	Layout      string `yaml:"layout"`
+	Fingerprint string `yaml:"fingerprint"`
+	Constants   string `yaml:"constants"`
+}
+
+// RevisionMatch tells how many parts of a known revision (the key header cells and
+// Constants) a sim shares with it.
+type RevisionMatch struct {
+	Revision Revision
+	Matched  int
+	Total    int
+}
+
+func (m RevisionMatch) Exact() bool {
+	return m.Total > 0 && m.Matched == m.Total
+}
+
+// KnownRevisions returns the sim revisions embedded from data/revisions.yml.
+func KnownRevisions() ([]Revision, error) {
+	content, err := data.FS.ReadFile(revisionsPath)
+	if err != nil {
+		return nil, WrapError(err, "error reading revisions")
+	}
+
+	return ParseRevisions(content)
+}
+
+func ParseRevisions(content []byte) ([]Revision, error) {
+	var file struct {
+		Revisions []Revision `yaml:"revisions"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, WrapError(err, "error parsing revisions")
+	}
+
+	for _, revision := range file.Revisions {
+		if revision.Name == "" || revision.Layout == "" || revision.Fingerprint == "" {
+			return nil, fmt.Errorf("revision %q needs a name, a layout and a fingerprint", revision.Name)
+		}
+	}
+
+	return file.Revisions, nil
+}
+
+// MatchRevisions sets Revision to the revision with the same fingerprint, or to the
+// one sharing most parts of it when the sim is not known. Revisions of layouts the
+// sim wasn't compared to are skipped.
+func (v *VersionCheck) MatchRevisions(revisions []Revision) {
+	v.Revision = nil
+
+	for _, revision := range revisions {
+		layoutMatch, ok := v.layoutMatch(revision.Layout)
+		if !ok {
+			continue
+		}
+
+		match := v.matchRevision(revision, layoutMatch)
+		if v.Revision == nil || match.Matched*v.Revision.Total > v.Revision.Matched*match.Total {
+			v.Revision = &match
+		}
+	}
+}
+
+func (v *VersionCheck) layoutMatch(revision string) (LayoutMatch, bool) {
+	for _, match := range v.Matches {
+		if match.Revision == revision {
+			return match, true
+		}
+	}
+
+	return LayoutMatch{}, false
+}
+
+func (v *VersionCheck) matchRevision(revision Revision, layoutMatch LayoutMatch) RevisionMatch {
+	match := RevisionMatch{Revision: revision, Matched: layoutMatch.Matched, Total: layoutMatch.Total}
+	if revision.Constants != "" {
+		match.Total++
+		if revision.Constants == v.ConstantsHash {
+			match.Matched++
+		}
+	}
+
+	// Headers can all match while sheets are missing or the layout was changed since
+	// the revision was added, that is not the same revision
+	if layoutMatch.Fingerprint != revision.Fingerprint && match.Matched == match.Total {
+		match.Matched--
+	}
+
+	return match
+}
+
+// revisionEntry is the data/revisions.yml entry of the sim for an unknown revision
+func (v *VersionCheck) revisionEntry(match LayoutMatch) string {
+	var sb strings.Builder
+
+	sb.WriteString("  - name: <revision name>\n")
+	sb.WriteString(fmt.Sprintf("    layout: %s\n", match.Revision))
+	sb.WriteString(fmt.Sprintf("    fingerprint: %s\n", match.Fingerprint))
+	sb.WriteString(fmt.Sprintf("    constants: %s\n", v.ConstantsHash))
+
+	return sb.String()
+}
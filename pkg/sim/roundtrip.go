@@ -0,0 +1,463 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RoundTripMismatch is an action of an hour that the parsed log and the sim cells
+// disagree on, Sim and Log are the amounts of both sides
+type RoundTripMismatch struct {
+	Hour    int    `json:"hour"`
+	Action  string `json:"action"`
+	Sim     int    `json:"sim"`
+	Log     int    `json:"log"`
+	Message string `json:"message,omitempty"`
+}
+
+func (m RoundTripMismatch) String() string {
+	if m.Hour == 0 {
+		return fmt.Sprintf("%s: %s", m.Action, m.Message)
+	}
+	if m.Message != "" {
+		return fmt.Sprintf("Hour %d: %s: %s", m.Hour, m.Action, m.Message)
+	}
+
+	return fmt.Sprintf("Hour %d: %s: sim %d, log %d", m.Hour, m.Action, m.Sim, m.Log)
+}
+
+// RoundTripReport is the result of generating the log of a sim and parsing it back
+type RoundTripReport struct {
+	Sim        string              `json:"sim"`
+	Hours      int                 `json:"hours"`
+	Mismatches []RoundTripMismatch `json:"mismatches"`
+}
+
+type RoundTripCmd struct {
+	simPath    string
+	resultPath string
+	format     string
+	gameLog    *GameLogCmd
+}
+
+// NewRoundTripCmd prepares the round trip check of a sim, validation is not run
+func NewRoundTripCmd(simPath, resultPath, format string, options GameLogOptions) (*RoundTripCmd, error) {
+	if format == "" {
+		format = FormatText
+	}
+	if err := checkFormat(format, FormatText, FormatJSON); err != nil {
+		return nil, err
+	}
+
+	gameLog, err := NewGameLog(simPath, resultPath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoundTripCmd{
+		simPath:    simPath,
+		resultPath: resultPath,
+		format:     format,
+		gameLog:    gameLog,
+	}, nil
+}
+
+// Execute writes the report, it returns an error when the log and the sim disagree
+func (c *RoundTripCmd) Execute() error {
+	defer c.gameLog.Close()
+
+	mismatches, err := c.gameLog.RoundTrip()
+	if err != nil {
+		return err
+	}
+
+	report := &RoundTripReport{
+		Sim:        c.simPath,
+		Hours:      c.gameLog.options.To - c.gameLog.options.From + 1,
+		Mismatches: mismatches,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRoundTripReport(&buf, report, c.format); err != nil {
+		return err
+	}
+
+	if err := writeResult(c.resultPath, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("the log of %s doesn't match the sim in %d actions", c.simPath, len(mismatches))
+	}
+
+	return nil
+}
+
+// RoundTrip generates the import log of the hour range, parses it back and compares
+// the parsed actions with the input cells of the sim hour by hour. Costs are not
+// compared, they are read from the sim by the generator. Lines of the log that can't
+// be parsed back are returned as the error.
+func (c *GameLogCmd) RoundTrip() ([]RoundTripMismatch, error) {
+	hours, err := c.generateHours()
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := ParseLog(strings.NewReader(RenderText(hours)))
+	if err != nil {
+		return nil, WrapError(err, "error parsing the generated log")
+	}
+
+	mismatches := []RoundTripMismatch{}
+
+	logActions := map[int]*roundTripActions{}
+	for _, hour := range parsed.Hours {
+		logActions[hour.Hour] = roundTripLogActions(hour.Events)
+	}
+
+	for hr := c.options.From; hr <= c.options.To; hr++ {
+		c.setCurrentHour(hr)
+
+		simActions, collisions, err := c.roundTripSimActions()
+		if err != nil {
+			return nil, err
+		}
+		for _, collision := range collisions {
+			collision.Hour = hr
+			mismatches = append(mismatches, collision)
+		}
+
+		if logActions[hr] == nil {
+			logActions[hr] = newRoundTripActions()
+		}
+		mismatches = append(mismatches, compareRoundTrip(hr, simActions, logActions[hr])...)
+	}
+
+	return mismatches, nil
+}
+
+// Names the game uses for units and buildings that differ from the sim by more than
+// case, spaces and plurals, applied after roundTripName normalized them
+var roundTripRenames = map[string]string{
+	"archspy":    "assassin",
+	"firespirit": "firesprite",
+	"guild":      "wizardguild",
+	"mermen":     "merman",
+	"voodoomagi": "voodoomage",
+}
+
+// roundTripName reduces a name to lowercase letters and digits in singular, so the
+// names of the sim and the keys of the parser are compared without valuesMap
+func roundTripName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+
+	normalized := sb.String()
+	switch {
+	case strings.HasSuffix(normalized, "ies"):
+		normalized = strings.TrimSuffix(normalized, "ies") + "y"
+	case strings.HasSuffix(normalized, "s") && !strings.HasSuffix(normalized, "ss"):
+		normalized = strings.TrimSuffix(normalized, "s")
+	}
+
+	if renamed, ok := roundTripRenames[normalized]; ok {
+		return renamed
+	}
+	return normalized
+}
+
+// roundTripActions sums the amounts of an hour by "type name" keys with normalized
+// names, the first name of every key is kept to report it
+type roundTripActions struct {
+	amounts map[string]int
+	names   map[string]string
+}
+
+func newRoundTripActions() *roundTripActions {
+	return &roundTripActions{amounts: map[string]int{}, names: map[string]string{}}
+}
+
+// add sums the amount under the key of the name and returns the key
+func (a *roundTripActions) add(actionType, name string, amount int) string {
+	key, label := actionType, actionType
+	if name != "" {
+		key = actionType + " " + roundTripName(name)
+		label = actionType + " " + name
+	}
+
+	if _, ok := a.names[key]; !ok {
+		a.names[key] = label
+	}
+	a.amounts[key] += amount
+
+	return key
+}
+
+// roundTripLogActions sums the parsed events, casts and unlocks count as 1 and the
+// daily land bonus is counted in acres. Names go through resultKey like in the
+// parsed results, costs are skipped.
+func roundTripLogActions(events []Event) *roundTripActions {
+	actions := newRoundTripActions()
+
+	addAmounts := func(actionType string, amounts []Amount) {
+		for _, amount := range amounts {
+			actions.add(actionType, resultKey(amount.Name), amount.Value)
+		}
+	}
+
+	for _, event := range events {
+		switch e := event.(type) {
+		case DraftRateChanged:
+			rate, _ := strconv.Atoi(strings.TrimSuffix(e.Rate, "%"))
+			actions.add(DRAFTRATE, "", rate)
+		case UnitsReleased:
+			addAmounts(RELEASE, e.Units)
+			if e.Draftees > 0 {
+				actions.add(RELEASE, resultKey("draftees"), e.Draftees)
+			}
+		case SpellCast:
+			actions.add(MAGIC, e.Spell, 1)
+		case TechUnlocked:
+			actions.add(TECH, e.Tech, 1)
+		case DailyPlatinum:
+			actions.add(DAILY, "platinum", 1)
+		case DailyLand:
+			actions.add(DAILY, "land", e.Acres)
+		case ResourcesTraded:
+			addAmounts(BANK, e.Resources)
+		case ExplorationStarted:
+			addAmounts(EXPLORE, e.Lands)
+		case BuildingsDestroyed:
+			addAmounts(DESTRUCTION, e.Buildings)
+		case Rezoned:
+			addAmounts(REZONE, e.Lands)
+		case ConstructionStarted:
+			addAmounts(CONSTRUCTION, e.Buildings)
+		case UnitsTrained:
+			addAmounts(TRAIN, e.Units)
+		case Invested:
+			actions.add(INVEST, e.Improvement+" "+e.Resource, e.Amount)
+		}
+	}
+
+	return actions
+}
+
+// roundTripSimActions reads the input cells of the current hour the same way as
+// roundTripLogActions sums the parsed log. Names are the text of the sim headers and
+// layout, they don't go through valuesMap like the parsed names so a wrong mapping
+// shows up as a mismatch. Different sim names with the same key are mismatches too.
+func (c *GameLogCmd) roundTripSimActions() (*roundTripActions, []RoundTripMismatch, error) {
+	actions := newRoundTripActions()
+	collisions := []RoundTripMismatch{}
+
+	readInt := func(sheet, cell string) (int, error) {
+		return c.readIntValue(sheet, cell, "error reading input cell")
+	}
+
+	// Draft rate is in the log when it's changed
+	rate, err := c.readField("draftrate", "error reading current draftrate")
+	if err != nil {
+		return nil, nil, err
+	}
+	previousField := c.layout.Field("previous_draftrate")
+	previous, err := c.readValue(previousField.Sheet, c.wrapHourAs(previousField.Column, c.simHour-1), "error reading previous draftrate")
+	if err != nil {
+		return nil, nil, err
+	}
+	if rate != "" && rate != previous {
+		percent, err := parsePercent(rate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading draftrate %q: %w", rate, err)
+		}
+		actions.add(DRAFTRATE, "", FloatToInt(percent))
+	}
+
+	groups := []struct {
+		actionType     string
+		group          string
+		nameFromHeader bool
+	}{
+		{RELEASE, "release", true},
+		{EXPLORE, "explore", false},
+		{DESTRUCTION, "destruction", false},
+		{REZONE, "rezone", false},
+		{CONSTRUCTION, "construction", false},
+		{TRAIN, "train", true},
+	}
+	for _, g := range groups {
+		group := c.layout.Group(g.group)
+
+		for _, col := range group.Columns {
+			value, err := readInt(group.Sheet, c.wrapHour(col.Column))
+			if err != nil {
+				return nil, nil, err
+			}
+			if value == 0 {
+				continue
+			}
+
+			name := col.Name
+			if g.nameFromHeader {
+				if name, err = c.readValue(group.Sheet, c.wrapHourAs(col.Column, group.HeaderRow), "error reading unit name"); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			key := actions.add(g.actionType, name, value)
+			if first := actions.names[key]; first != g.actionType+" "+name {
+				collisions = append(collisions, RoundTripMismatch{
+					Action:  first,
+					Message: fmt.Sprintf("%s and %s are parsed as the same name", strings.TrimPrefix(first, g.actionType+" "), name),
+				})
+			}
+		}
+	}
+
+	draftees, err := c.readIntField("release_draftees", "error reading draftees value")
+	if err != nil {
+		return nil, nil, err
+	}
+	if draftees > 0 {
+		actions.add(RELEASE, "draftees", draftees)
+	}
+
+	spells := c.layout.Group("spells")
+	for _, col := range spells.Columns {
+		value, err := readInt(spells.Sheet, c.wrapHour(col.Column))
+		if err != nil {
+			return nil, nil, err
+		}
+		if value == 0 {
+			continue
+		}
+
+		name := col.Name
+		if col.Racial {
+			spell, err := c.racialSpell()
+			if err != nil {
+				return nil, nil, err
+			}
+			name = spell.Name
+		}
+		actions.add(MAGIC, name, 1)
+	}
+
+	unlocked, err := c.readIntField("tech_unlocked", "error reading tech status")
+	if err != nil {
+		return nil, nil, err
+	}
+	if unlocked > 0 {
+		tech, err := c.readField("tech_name", "error reading tech name")
+		if err != nil {
+			return nil, nil, err
+		}
+		actions.add(TECH, tech, 1)
+	}
+
+	for _, field := range []string{"daily_platinum", "land_bonus"} {
+		checked, err := c.readIntField(field, "error reading daily bonus")
+		if err != nil {
+			return nil, nil, err
+		}
+		if checked == 0 {
+			continue
+		}
+		if field == "daily_platinum" {
+			actions.add(DAILY, "platinum", 1)
+		} else {
+			actions.add(DAILY, "land", LandBonus)
+		}
+	}
+
+	for _, resource := range tradeResources {
+		value, err := c.readIntField("trade_"+resource, "error reading trade")
+		if err != nil {
+			return nil, nil, err
+		}
+		if value != 0 {
+			actions.add(BANK, resource, value)
+		}
+	}
+
+	improvements := c.layout.Group("improvements")
+	for _, col := range improvements.Columns {
+		amount, err := readInt(improvements.Sheet, c.wrapHour(col.Column))
+		if err != nil {
+			return nil, nil, err
+		}
+		if amount == 0 {
+			continue
+		}
+
+		resource, err := c.readValue(improvements.Sheet, c.wrapHour(col.Resource), "error reading improvement resource")
+		if err != nil {
+			return nil, nil, err
+		}
+		target, err := c.readValue(improvements.Sheet, c.wrapHour(col.Target), "error reading improvement")
+		if err != nil {
+			return nil, nil, err
+		}
+		actions.add(INVEST, target+" "+resource, amount)
+	}
+
+	return actions, collisions, nil
+}
+
+// compareRoundTrip lists the actions of an hour with different amounts in the sim and the log
+func compareRoundTrip(hr int, simActions, logActions *roundTripActions) []RoundTripMismatch {
+	keys := map[string]bool{}
+	for key := range simActions.amounts {
+		keys[key] = true
+	}
+	for key := range logActions.amounts {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	mismatches := []RoundTripMismatch{}
+	for _, key := range sorted {
+		simAmount, logAmount := simActions.amounts[key], logActions.amounts[key]
+		if simAmount == logAmount {
+			continue
+		}
+
+		action, ok := simActions.names[key]
+		if !ok {
+			action = logActions.names[key]
+		}
+		mismatches = append(mismatches, RoundTripMismatch{Hour: hr, Action: action, Sim: simAmount, Log: logAmount})
+	}
+
+	return mismatches
+}
+
+// WriteRoundTripReport writes the mismatches as text lines or JSON
+func WriteRoundTripReport(w io.Writer, report *RoundTripReport, format string) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	for _, mismatch := range report.Mismatches {
+		fmt.Fprintln(w, mismatch)
+	}
+	fmt.Fprintf(w, "%s: %d hours, %d mismatches\n", report.Sim, report.Hours, len(report.Mismatches))
+
+	return nil
+}
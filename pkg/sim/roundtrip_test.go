@@ -0,0 +1,113 @@
+package sim
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	simData := map[string]map[string]string{
+		Overview:     {"B15": "5/18/2024"},
+		Imps:         {},
+		Construction: {"O4": "5", "AQ4": "1000"},
+		// Both unit names are parsed as Icebeast
+		Military: {"AG2": "Ice Beast", "AH2": "Icebeast", "AG5": "10", "AH5": "5", "AR5": "5000"},
+		// Rezoning without a cost is not generated
+		Rezone:     {"L5": "3"},
+		Production: {"BC4": "-1000", "BF4": "20"},
+	}
+	for hr := 1; hr <= 2; hr++ {
+		simData[Imps][fmt.Sprintf("BY%d", hr+3)] = "18:00"
+		simData[Imps][fmt.Sprintf("BZ%d", hr+3)] = "00:00"
+	}
+
+	glc := newMockGameLog(&SimMock{Data: simData, AllowMissing: true})
+	glc.initActions()
+	glc.options = GameLogOptions{From: 1, To: 2}
+
+	mismatches, err := glc.RoundTrip()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := []string{}
+	for _, mismatch := range mismatches {
+		got = append(got, mismatch.String())
+	}
+	expected := []string{
+		"Hour 2: train Ice Beast: Ice Beast and Icebeast are parsed as the same name",
+		"Hour 2: rezone Plains: sim 3, log 0",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Incorrect mismatches:\n got %q\nwant %q", got, expected)
+	}
+}
+
+func TestRoundTripWrongValuesMap(t *testing.T) {
+	// Alchemies are parsed as farms by mistake, the sim names don't use the mapping
+	valuesMap["Alchemies"] = "farm"
+	defer func() { valuesMap["Alchemies"] = "alchemy" }()
+
+	simData := map[string]map[string]string{
+		Overview:     {"B15": "5/18/2024"},
+		Imps:         {"BY4": "18:00", "BZ4": "00:00"},
+		Construction: {"P4": "5", "AQ4": "1000"},
+	}
+
+	glc := newMockGameLog(&SimMock{Data: simData, AllowMissing: true})
+	glc.initActions()
+	glc.options = GameLogOptions{From: 1, To: 1}
+
+	mismatches, err := glc.RoundTrip()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := []string{}
+	for _, mismatch := range mismatches {
+		got = append(got, mismatch.String())
+	}
+	expected := []string{
+		"Hour 1: construction Alchemies: sim 5, log 0",
+		"Hour 1: construction farm: sim 0, log 5",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Incorrect mismatches:\n got %q\nwant %q", got, expected)
+	}
+}
+
+func TestRoundTripName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Lumber Yards": "lumberyard",
+		"lumberyard":   "lumberyard",
+		"Archspies":    "assassin",
+		"assassins":    "assassin",
+		"Barracks":     "barrack",
+		"Gaias Watch":  "gaiaswatch",
+		"Gaia's Watch": "gaiaswatch",
+	} {
+		if got := roundTripName(name); got != expected {
+			t.Errorf("Incorrect name of %q: got %q, want %q", name, got, expected)
+		}
+	}
+}
+
+func TestWriteRoundTripReport(t *testing.T) {
+	report := &RoundTripReport{
+		Sim:        "sim.xlsm",
+		Hours:      73,
+		Mismatches: []RoundTripMismatch{{Hour: 3, Action: "construction Homes", Sim: 10, Log: 5}},
+	}
+
+	var sb strings.Builder
+	if err := WriteRoundTripReport(&sb, report, FormatText); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "Hour 3: construction Homes: sim 10, log 5\nsim.xlsm: 73 hours, 1 mismatches\n"
+	if sb.String() != expected {
+		t.Errorf("Incorrect report:\n got %q\nwant %q", sb.String(), expected)
+	}
+}
@@ -0,0 +1,206 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rxx/od_tools/data"
+	"gopkg.in/yaml.v3"
+)
+
+const spellsPath = "spells.yml"
+
+// SpellData is a spell of the game from data/spells.yml, mana cost is per acre of land
+type SpellData struct {
+	Key      string   `yaml:"-"`
+	Name     string   `yaml:"name"`
+	Category string   `yaml:"category"`
+	CostMana float64  `yaml:"cost_mana"`
+	Races    []string `yaml:"races"`
+	Active   *bool    `yaml:"active"`
+}
+
+// ManaCost is the mana needed to cast the spell with the land of the dominion
+func (s SpellData) ManaCost(land int) int {
+	return FloatToInt(s.CostMana * float64(land))
+}
+
+// LoadSpells reads the spells of the game keyed by their names
+func LoadSpells() (map[string]SpellData, error) {
+	content, err := data.FS.ReadFile(spellsPath)
+	if err != nil {
+		return nil, WrapError(err, "error reading spells")
+	}
+
+	spellsByKey := map[string]SpellData{}
+	if err := yaml.Unmarshal(content, &spellsByKey); err != nil {
+		return nil, WrapError(err, "error parsing spells")
+	}
+
+	keys := make([]string, 0, len(spellsByKey))
+	for key := range spellsByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	spells := map[string]SpellData{}
+	for _, key := range keys {
+		spell := spellsByKey[key]
+		spell.Key = key
+		if _, ok := spells[spell.Name]; !ok {
+			spells[spell.Name] = spell
+		}
+	}
+
+	return spells, nil
+}
+
+// spellCast is a spell cast in a protection hour with its mana cost
+type spellCast struct {
+	Spell  string
+	Column LayoutColumn
+	Cell   string
+	Land   int
+	Cost   int
+}
+
+// spellCasts returns the spells cast in the hour with the land of the dominion at cast
+// time, see landAtCast.
+func (c *GameLogCmd) spellCasts(hr int) ([]spellCast, error) {
+	if c.spells == nil {
+		spells, err := LoadSpells()
+		if err != nil {
+			return nil, err
+		}
+		c.spells = spells
+	}
+
+	row := c.hourRow(hr)
+	group := c.layout.Group("spells")
+
+	casts := []spellCast{}
+	land := -1
+
+	for _, col := range group.Columns {
+		cell := c.wrapHourAs(col.Column, row)
+		value, err := c.readIntValue(group.Sheet, cell, "error on reading magic cell")
+		if err != nil {
+			return nil, err
+		}
+		if value == 0 {
+			continue
+		}
+
+		if land < 0 {
+			if land, err = c.landAtCast(hr); err != nil {
+				return nil, err
+			}
+		}
+
+		cast := spellCast{Spell: col.Name, Column: col, Cell: cell, Land: land}
+
+		// The racial spell is the self spell of the race of the sim
+		if col.Racial {
+			spell, err := c.racialSpell()
+			if err != nil {
+				return nil, err
+			}
+			cast.Spell = spell.Name
+			cast.Cost = spell.ManaCost(land)
+		} else {
+			spell, ok := c.spells[col.Name]
+			if !ok {
+				return nil, fmt.Errorf("unknown spell %q of %s!%s, it's not in %s", col.Name, group.Sheet, cell, spellsPath)
+			}
+			cast.Cost = spell.ManaCost(land)
+		}
+
+		casts = append(casts, cast)
+	}
+
+	return casts, nil
+}
+
+// racialSpell returns the racial spell of the race set in the sim
+func (c *GameLogCmd) racialSpell() (SpellData, error) {
+	if c.racial != nil {
+		return *c.racial, nil
+	}
+
+	if _, ok := c.layout.Fields["race"]; !ok {
+		return SpellData{}, fmt.Errorf("the layout has no race field to find the racial spell")
+	}
+
+	race, err := c.readField("race", "error reading race")
+	if err != nil {
+		return SpellData{}, err
+	}
+	if race == "" {
+		sheet, cell := c.fieldCell("race")
+		return SpellData{}, fmt.Errorf("no race in %s!%s to find the racial spell", sheet, cell)
+	}
+
+	races, err := LoadRaces()
+	if err != nil {
+		return SpellData{}, err
+	}
+
+	spell, err := FindRacialSpell(c.spells, races, race)
+	if err != nil {
+		return SpellData{}, err
+	}
+	c.racial = &spell
+
+	return spell, nil
+}
+
+// manaDeducted reads the mana the sim deducts for spells in the hour, it's the mana
+// of the previous hour plus the production of the hour minus the mana left.
+// Available is the mana the spells of the hour can be cast with.
+func (c *GameLogCmd) manaDeducted(hr int) (deducted, available int, err error) {
+	manaField := c.layout.Field("mana")
+	previous, err := c.readIntValue(manaField.Sheet, c.wrapHourAs(manaField.Column, c.hourRow(hr-1)), "error reading mana")
+	if err != nil {
+		return 0, 0, err
+	}
+	mana, err := c.readIntValue(manaField.Sheet, c.wrapHourAs(manaField.Column, c.hourRow(hr)), "error reading mana")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	productionSheet, productionCell := c.fieldCellAt("mana_production", c.hourRow(hr))
+	production, err := c.readIntValue(productionSheet, productionCell, "error reading mana production")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	available = previous + production
+	return available - mana, available, nil
+}
+
+// landAtCast is the land of the dominion when spells are cast in the hour, the total land
+// of the previous hour plus the land explored ExploreHours before that arrives in the hour.
+// Spells are cast before the daily land bonus is claimed and exploration of the hour starts.
+func (c *GameLogCmd) landAtCast(hr int) (int, error) {
+	landSheet, landCell := c.fieldCellAt("land_size", c.hourRow(hr-1))
+	land, err := c.readIntValue(landSheet, landCell, "error reading land size")
+	if err != nil {
+		return 0, err
+	}
+
+	explored := hr - ExploreHours
+	if explored < 1 {
+		return land, nil
+	}
+
+	explore := c.layout.Group("explore")
+	for _, col := range explore.Columns {
+		acres, err := c.readIntValue(explore.Sheet, c.wrapHourAs(col.Column, c.hourRow(explored)), "error reading explored land")
+		if err != nil {
+			return 0, err
+		}
+		land += acres
+	}
+
+	return land, nil
+}
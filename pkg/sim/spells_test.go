@@ -0,0 +1,101 @@
+package sim
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadSpells(t *testing.T) {
+	spells, err := LoadSpells()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, column := range mustDefaultLayout().Group("spells").Columns {
+		if column.Racial {
+			continue
+		}
+		if _, ok := spells[column.Name]; !ok {
+			t.Errorf("Spell %q of the layout is missing", column.Name)
+		}
+	}
+
+	gaiasWatch := spells[GaiasWatch]
+	if gaiasWatch.Key != "gaias_watch" || gaiasWatch.ManaCost(250) != 500 {
+		t.Errorf("Incorrect spell: %+v", gaiasWatch)
+	}
+}
+
+func TestSpellCasts(t *testing.T) {
+	sim := &SimMock{
+		AllowMissing: true,
+		Data: map[string]map[string]string{
+			Magic:    {"G4": "1", "L4": "1", "I5": "1", "G16": "1"},
+			Explore:  {"B3": "250", "B4": "300", "S4": "20", "T4": "20", "B15": "280"},
+			Overview: {"B14": "Sylvan"},
+		},
+	}
+	glc := newMockGameLog(sim)
+
+	casts, err := glc.spellCasts(1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := []string{}
+	for _, cast := range casts {
+		got = append(got, cast.Spell+"@"+cast.Cell)
+		if cast.Land != 250 {
+			t.Errorf("Land of the previous hour should be used: got %d acres", cast.Land)
+		}
+	}
+	if expected := []string{"Gaia's Watch@G4", "Verdant Bloom@L4"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Incorrect casts: got %v, want %v", got, expected)
+	}
+	if casts[0].Cost != 500 || casts[1].Cost != 1250 {
+		t.Errorf("Incorrect mana costs: %+v", casts)
+	}
+
+	casts, err = glc.spellCasts(2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(casts) != 1 || casts[0].Cost != 750 {
+		t.Errorf("Incorrect cast of Ares' Call: %+v", casts)
+	}
+
+	// The 20 acres explored in hour 1 arrive in hour 13
+	casts, err = glc.spellCasts(13)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(casts) != 1 || casts[0].Land != 300 || casts[0].Cost != 600 {
+		t.Errorf("Incorrect cast with arriving land: %+v", casts)
+	}
+
+	sim.Data[Magic]["M6"] = "1"
+	sim.Data[Overview]["B14"] = "Pixie"
+	glc.racial = nil
+	if _, err := glc.spellCasts(3); err == nil || !strings.Contains(err.Error(), `unknown race "Pixie"`) {
+		t.Errorf("Expected an error for the unknown race, got %v", err)
+	}
+}
+
+func TestManaDeducted(t *testing.T) {
+	glc := newMockGameLog(&SimMock{
+		AllowMissing: true,
+		Data: map[string]map[string]string{
+			Production: {"K3": "1000", "R4": "150", "K4": "400"},
+		},
+	})
+
+	// The sim deducts 1000 + 150 - 400
+	deducted, available, err := glc.manaDeducted(1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deducted != 750 || available != 1150 {
+		t.Errorf("Incorrect mana: deducted %d, available %d", deducted, available)
+	}
+}
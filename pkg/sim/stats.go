@@ -0,0 +1,337 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// DefaultStatsHour is shown when neither -hour nor the sim sets the hour
+const DefaultStatsHour = 72
+
+// DominionStats is the status of the dominion at the end of a protection hour
+type DominionStats struct {
+	Hour   int            `json:"hour"`
+	Status DominionStatus `json:"status"`
+}
+
+// DominionStatus has the names of the OpenDominion status JSON, see calc/src/types/stats-json.ts
+type DominionStatus struct {
+	Name              string  `json:"name"`
+	RaceName          string  `json:"race_name"`
+	Land              int     `json:"land"`
+	Peasants          int     `json:"peasants"`
+	Employment        float64 `json:"employment"`
+	Networth          int     `json:"networth"`
+	ResourcePlatinum  int     `json:"resource_platinum"`
+	ResourceFood      int     `json:"resource_food"`
+	ResourceLumber    int     `json:"resource_lumber"`
+	ResourceMana      int     `json:"resource_mana"`
+	ResourceOre       int     `json:"resource_ore"`
+	ResourceGems      int     `json:"resource_gems"`
+	ResourceBoats     int     `json:"resource_boats"`
+	Morale            int     `json:"morale"`
+	MilitaryDraftees  int     `json:"military_draftees"`
+	MilitaryUnit1     int     `json:"military_unit1"`
+	MilitaryUnit2     int     `json:"military_unit2"`
+	MilitaryUnit3     int     `json:"military_unit3"`
+	MilitaryUnit4     int     `json:"military_unit4"`
+	MilitarySpies     int     `json:"military_spies"`
+	MilitaryAssassins int     `json:"military_assassins"`
+	MilitaryWizards   int     `json:"military_wizards"`
+	MilitaryArchmages int     `json:"military_archmages"`
+
+	// Unit names of the sim for the text status
+	UnitNames [4]string `json:"-"`
+}
+
+// SimDominionName is the name of the dominion in the status, the sim has no ruler
+const SimDominionName = "Simulated Dominion"
+
+type StatsCmd struct {
+	simPath    string
+	resultPath string
+	hour       int
+	format     string
+	gameLog    *GameLogCmd
+}
+
+// NewStatsCmd prepares reading the status of an hour, hour 0 uses the hour set in the sim
+func NewStatsCmd(simPath, resultPath string, hour int, format string, options GameLogOptions) (*StatsCmd, error) {
+	if format == "" {
+		format = FormatText
+	}
+	if err := checkFormat(format, FormatText, FormatJSON); err != nil {
+		return nil, err
+	}
+
+	if hour < 0 || hour >= LastHour {
+		return nil, fmt.Errorf("invalid hour %d, the status is shown for hours from 1 to %d", hour, LastHour-1)
+	}
+
+	gameLog, err := NewGameLog(simPath, resultPath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsCmd{
+		simPath:    simPath,
+		resultPath: resultPath,
+		hour:       hour,
+		format:     format,
+		gameLog:    gameLog,
+	}, nil
+}
+
+func (c *StatsCmd) Execute() error {
+	defer c.gameLog.Close()
+
+	stats, err := c.gameLog.ReadStats(c.hour)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStats(&buf, stats, c.format); err != nil {
+		return err
+	}
+
+	return writeResult(c.resultPath, buf.Bytes())
+}
+
+// ReadStats reads the status at the end of the hour, the sim is not changed.
+// Hour 0 is read from the log_hour field of the sim.
+func (c *GameLogCmd) ReadStats(hour int) (*DominionStats, error) {
+	fields := c.layout.Stats
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("the layout has no stats, add a stats section to read the status")
+	}
+
+	if hour == 0 {
+		var err error
+		if hour, err = c.statsHour(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Values of an hour are in the row of the next one
+	row := c.hourRow(hour + 1)
+
+	readValue := func(name string) (string, error) {
+		field, ok := fields[name]
+		if !ok {
+			return "", fmt.Errorf("the layout has no stat %q", name)
+		}
+
+		cell := field.Cell
+		if cell == "" {
+			cell = c.wrapHourAs(field.Column, row)
+		}
+		return c.readValue(field.Sheet, cell, "error reading "+name)
+	}
+
+	var readErr error
+	readInt := func(name string) int {
+		if readErr != nil {
+			return 0
+		}
+
+		value, err := readValue(name)
+		if err != nil {
+			readErr = err
+			return 0
+		}
+
+		number, err := parseStatNumber(value)
+		if err != nil {
+			readErr = fmt.Errorf("error reading %s: %w", name, err)
+			return 0
+		}
+		return FloatToInt(number)
+	}
+
+	status := DominionStatus{
+		Name:              SimDominionName,
+		Land:              readInt("land"),
+		Peasants:          readInt("peasants"),
+		Networth:          readInt("networth"),
+		ResourcePlatinum:  readInt("resource_platinum"),
+		ResourceFood:      readInt("resource_food"),
+		ResourceLumber:    readInt("resource_lumber"),
+		ResourceMana:      readInt("resource_mana"),
+		ResourceOre:       readInt("resource_ore"),
+		ResourceGems:      readInt("resource_gems"),
+		ResourceBoats:     readInt("resource_boats"),
+		MilitaryDraftees:  readInt("military_draftees"),
+		MilitaryUnit1:     readInt("military_unit1"),
+		MilitaryUnit2:     readInt("military_unit2"),
+		MilitaryUnit3:     readInt("military_unit3"),
+		MilitaryUnit4:     readInt("military_unit4"),
+		MilitarySpies:     readInt("military_spies"),
+		MilitaryAssassins: readInt("military_assassins"),
+		MilitaryWizards:   readInt("military_wizards"),
+		MilitaryArchmages: readInt("military_archmages"),
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	raceName, err := readValue("race_name")
+	if err != nil {
+		return nil, err
+	}
+	status.RaceName = raceName
+
+	employment, err := readValue("employment")
+	if err != nil {
+		return nil, err
+	}
+	if status.Employment, err = parsePercent(employment); err != nil {
+		return nil, fmt.Errorf("error reading employment: %w", err)
+	}
+
+	morale, err := readValue("morale")
+	if err != nil {
+		return nil, err
+	}
+	moralePercent, err := parsePercent(morale)
+	if err != nil {
+		return nil, fmt.Errorf("error reading morale: %w", err)
+	}
+	status.Morale = FloatToInt(moralePercent)
+
+	// Units are named the same way as in the log
+	release := c.layout.Group("release")
+	for i := range status.UnitNames {
+		status.UnitNames[i] = fmt.Sprintf("Unit%d", i+1)
+		if i >= len(release.Columns) {
+			continue
+		}
+
+		name, err := c.readValue(release.Sheet, c.wrapHourAs(release.Columns[i].Column, release.HeaderRow), "error reading unit name")
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			status.UnitNames[i] = name
+		}
+	}
+
+	return &DominionStats{Hour: hour, Status: status}, nil
+}
+
+// statsHour returns the hour set in the sim or the default one
+func (c *GameLogCmd) statsHour() (int, error) {
+	field, ok := c.layout.Fields["log_hour"]
+	if !ok || field.Cell == "" {
+		return DefaultStatsHour, nil
+	}
+
+	hour, err := c.readIntValue(field.Sheet, field.Cell, "error reading log hour")
+	if err != nil {
+		return 0, err
+	}
+	if hour < 1 || hour >= LastHour {
+		return DefaultStatsHour, nil
+	}
+
+	return hour, nil
+}
+
+// parseStatNumber reads numbers formatted by the sim like "1,234" or "→ 5"
+func parseStatNumber(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.Trim(value, "→ "), ",", "")
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// parsePercent reads "95.5%" or a fraction like 0.955 as 95.5
+func parsePercent(value string) (float64, error) {
+	if strings.HasSuffix(value, "%") {
+		return parseStatNumber(strings.TrimSuffix(value, "%"))
+	}
+
+	number, err := parseStatNumber(value)
+	return number * 100, err
+}
+
+// WriteStats writes the status as the OpenDominion status page or as JSON
+func WriteStats(w io.Writer, stats *DominionStats, format string) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	s := stats.Status
+
+	fmt.Fprintf(w, "The Dominion of %s: Hour %d\n", s.Name, stats.Hour)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	section := func(title string, lines ...string) {
+		fmt.Fprintf(table, "\n%s\n", title)
+		for _, line := range lines {
+			fmt.Fprintln(table, line)
+		}
+	}
+	line := func(label string, value int) string {
+		return fmt.Sprintf("%s:\t%s", label, formatThousands(value))
+	}
+
+	section("Overview",
+		"Race:\t"+s.RaceName,
+		line("Land", s.Land),
+		line("Peasants", s.Peasants),
+		fmt.Sprintf("Employment:\t%.2f%%", s.Employment),
+		line("Networth", s.Networth),
+	)
+	section("Resources",
+		line("Platinum", s.ResourcePlatinum),
+		line("Food", s.ResourceFood),
+		line("Lumber", s.ResourceLumber),
+		line("Mana", s.ResourceMana),
+		line("Ore", s.ResourceOre),
+		line("Gems", s.ResourceGems),
+		line("Boats", s.ResourceBoats),
+	)
+	section("Military",
+		fmt.Sprintf("Morale:\t%d%%", s.Morale),
+		line("Draftees", s.MilitaryDraftees),
+		line(s.UnitNames[0], s.MilitaryUnit1),
+		line(s.UnitNames[1], s.MilitaryUnit2),
+		line(s.UnitNames[2], s.MilitaryUnit3),
+		line(s.UnitNames[3], s.MilitaryUnit4),
+		line("Spies", s.MilitarySpies),
+		line("Archspies", s.MilitaryAssassins),
+		line("Wizards", s.MilitaryWizards),
+		line("Archmages", s.MilitaryArchmages),
+	)
+
+	return table.Flush()
+}
+
+// formatThousands formats a number with comma separators like the game
+func formatThousands(value int) string {
+	digits := strconv.Itoa(value)
+	sign := ""
+	if value < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var sb strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+
+	return sign + sb.String()
+}
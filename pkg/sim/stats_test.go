@@ -0,0 +1,132 @@
+package sim
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newStatsSimMock() *SimMock {
+	return &SimMock{
+		AllowMissing: true,
+		Data: map[string]map[string]string{
+			Overview:   {"B14": "Sylvan", "I28": "2"},
+			Population: {"C6": "1,004", "E6": "25", "I6": "95.50%"},
+			Production: {"E6": "270", "G6": "12,345", "H6": "100,000", "K6": "1000", "N6": "3"},
+			Military: {
+				"AX2": "Satyr", "AY2": "Sprite", "AZ2": "Dryad", "BA2": "Centaur",
+				"D6": "95%", "E6": "100", "H6": "5", "J6": "7", "L6": "1",
+			},
+		},
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	gameLog := newMockGameLog(newStatsSimMock())
+
+	stats, err := gameLog.ReadStats(2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := DominionStatus{
+		Name:              SimDominionName,
+		RaceName:          "Sylvan",
+		Land:              270,
+		Peasants:          1004,
+		Employment:        95.5,
+		Networth:          12345,
+		ResourcePlatinum:  100000,
+		ResourceMana:      1000,
+		ResourceBoats:     3,
+		Morale:            95,
+		MilitaryDraftees:  25,
+		MilitaryUnit1:     100,
+		MilitaryUnit4:     5,
+		MilitaryAssassins: 7,
+		MilitaryArchmages: 1,
+		UnitNames:         [4]string{"Satyr", "Sprite", "Dryad", "Centaur"},
+	}
+
+	if stats.Hour != 2 || stats.Status != expected {
+		t.Errorf("Incorrect stats:\n got %+v\nwant %+v", stats.Status, expected)
+	}
+
+	// The hour is taken from the sim
+	stats, err = gameLog.ReadStats(0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stats.Hour != 2 || stats.Status.Land != 270 {
+		t.Errorf("Expected the stats of hour 2, got hour %d with land %d", stats.Hour, stats.Status.Land)
+	}
+}
+
+func TestReadStatsLayoutWithoutStats(t *testing.T) {
+	gameLog := newMockGameLog(newStatsSimMock())
+	gameLog.layout = gameLog.layout.clone()
+	delete(gameLog.layout.Stats, "morale")
+
+	if _, err := gameLog.ReadStats(2); err == nil || !strings.Contains(err.Error(), `no stat "morale"`) {
+		t.Errorf("Expected an error for the missing morale stat, got %v", err)
+	}
+
+	gameLog.layout.Stats = nil
+	if _, err := gameLog.ReadStats(2); err == nil || !strings.Contains(err.Error(), "no stats") {
+		t.Errorf("Expected an error for a layout without stats, got %v", err)
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	stats, err := newMockGameLog(newStatsSimMock()).ReadStats(2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var sb strings.Builder
+	if err := WriteStats(&sb, stats, FormatText); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	text := sb.String()
+	for _, line := range []string{
+		"The Dominion of Simulated Dominion: Hour 2\n",
+		"Race:        Sylvan\n",
+		"Employment:  95.50%\n",
+		"Platinum:  100,000\n",
+		"Satyr:      100\n",
+		"Morale:     95%\n",
+		"Archspies:  7\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Status should contain %q, got:\n%s", line, text)
+		}
+	}
+
+	sb.Reset()
+	if err := WriteStats(&sb, stats, FormatJSON); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var status struct {
+		Hour   int            `json:"hour"`
+		Status map[string]any `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(sb.String()), &status); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if status.Status["resource_platinum"] != float64(100000) || status.Status["military_unit1"] != float64(100) {
+		t.Errorf("Incorrect JSON status: %v", status.Status)
+	}
+	if _, ok := status.Status["UnitNames"]; ok {
+		t.Error("Unit names should not be in the JSON status")
+	}
+}
+
+func TestFormatThousands(t *testing.T) {
+	for value, expected := range map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -12345: "-12,345"} {
+		if result := formatThousands(value); result != expected {
+			t.Errorf("formatThousands(%d) = %q, want %q", value, result, expected)
+		}
+	}
+}
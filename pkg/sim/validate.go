@@ -0,0 +1,449 @@
+package sim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+
+	MaxDraftRate = 90
+)
+
+// Protection hours where the daily platinum and land bonuses can be claimed once, a day
+// of 24 hours each. The first day is rows 4-27 of the sim, hour 73 is after protection
+// and starts a new day.
+var dailyBonusWindows = [][2]int{{1, 24}, {25, 48}, {49, 72}, {73, LastHour}}
+
+var tradeResources = []string{"platinum", "lumber", "ore", "gems"}
+
+// Exchange rates of the bank, selling resource => bought resource => rate
+var tradeRates = map[string]map[string]float64{
+	"platinum": {"lumber": 0.5, "ore": 0.5},
+	"lumber":   {"platinum": 0.5, "ore": 0.5},
+	"gems":     {"platinum": 2, "lumber": 2, "ore": 2},
+}
+
+// Violation is a problem found in the sim that would break the import in the game.
+type Violation struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Hour     int    `json:"hour"`
+	Sheet    string `json:"sheet"`
+	Cell     string `json:"cell"`
+	Value    string `json:"value"`
+	Message  string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Sheet == "" {
+		return fmt.Sprintf("%s: %s: %s", v.Severity, v.Location(), v.Message)
+	}
+	return fmt.Sprintf("%s: %s = %q: %s", v.Severity, v.Location(), v.Value, v.Message)
+}
+
+// Location is the hour and cell of the violation, or the layout for a skipped rule
+func (v Violation) Location() string {
+	if v.Sheet == "" {
+		return "layout"
+	}
+	return fmt.Sprintf("hour %d %s!%s", v.Hour, v.Sheet, v.Cell)
+}
+
+// RuleFunc checks the protection hours of the sim being generated and returns found violations
+type RuleFunc func() ([]Violation, error)
+
+func (c *GameLogCmd) initRules() {
+	c.rules = []RuleFunc{
+		c.draftRateRule,
+		c.militaryUnitsRule,
+		c.manaRule,
+		c.manaCostRule,
+		c.dailyBonusRule,
+		c.tradeRule,
+		c.populationRule,
+		c.buildingsRule,
+	}
+}
+
+// AddRule adds an extra rule to the ones checked before generation
+func (c *GameLogCmd) AddRule(rule RuleFunc) {
+	c.rules = append(c.rules, rule)
+}
+
+func (c *GameLogCmd) validateSim() ([]Violation, error) {
+	violations := []Violation{}
+
+	for _, rule := range c.rules {
+		found, err := rule()
+		if err != nil {
+			return nil, WrapError(err, "error on validating sim")
+		}
+		violations = append(violations, found...)
+	}
+
+	return violations, nil
+}
+
+// ruleHours returns the range of hours checked by the rules, the hours of the log.
+// Rules comparing an hour with the previous ones still read them.
+func (c *GameLogCmd) ruleHours() (from, to int) {
+	from, to = c.options.From, c.options.To
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 {
+		to = LastHour
+	}
+
+	return from, to
+}
+
+func hasErrors(violations []Violation) bool {
+	for _, violation := range violations {
+		if violation.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func newViolation(rule string, hour int, sheet, cell, value, message string) Violation {
+	return Violation{
+		Severity: SeverityError,
+		Rule:     rule,
+		Hour:     hour,
+		Sheet:    sheet,
+		Cell:     cell,
+		Value:    value,
+		Message:  message,
+	}
+}
+
+// checkNotNegative reports every hour where one of the columns is below zero
+func (c *GameLogCmd) checkNotNegative(rule, sheet string, cols []LayoutColumn, message string) ([]Violation, error) {
+	violations := []Violation{}
+	from, to := c.ruleHours()
+
+	for hr := from; hr <= to; hr++ {
+		for _, col := range cols {
+			cell := c.wrapHourAs(col.Column, c.hourRow(hr))
+			value, err := c.readIntValue(sheet, cell, "error reading "+rule+" value")
+			if err != nil {
+				return nil, err
+			}
+			if value >= 0 {
+				continue
+			}
+
+			msg := message
+			if col.Name != "" {
+				msg = fmt.Sprintf("%s (%s)", message, col.Name)
+			}
+			violations = append(violations, newViolation(rule, hr, sheet, cell, strconv.Itoa(value), msg))
+		}
+	}
+
+	return violations, nil
+}
+
+// skippedRule returns a warning when the layout has no field or group the rule
+// needs, they are optional in layouts and the rule is not checked then
+func (c *GameLogCmd) skippedRule(rule string, fields, groups []string) []Violation {
+	missing := []string{}
+	for _, name := range fields {
+		if _, ok := c.layout.Fields[name]; !ok {
+			missing = append(missing, "field "+name)
+		}
+	}
+	for _, name := range groups {
+		if _, ok := c.layout.Groups[name]; !ok {
+			missing = append(missing, "group "+name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return []Violation{{
+		Severity: SeverityWarning,
+		Rule:     rule,
+		Message:  fmt.Sprintf("the layout has no %s, the rule is skipped", strings.Join(missing, ", ")),
+	}}
+}
+
+func (c *GameLogCmd) checkFieldNotNegative(rule, fieldName, message string) ([]Violation, error) {
+	if skipped := c.skippedRule(rule, []string{fieldName}, nil); skipped != nil {
+		return skipped, nil
+	}
+
+	field := c.layout.Field(fieldName)
+	return c.checkNotNegative(rule, field.Sheet, []LayoutColumn{{Column: field.Column}}, message)
+}
+
+func (c *GameLogCmd) draftRateRule() ([]Violation, error) {
+	violations := []Violation{}
+	field := c.layout.Field("previous_draftrate")
+	from, to := c.ruleHours()
+
+	for hr := from; hr <= to; hr++ {
+		cell := c.wrapHourAs(field.Column, c.hourRow(hr))
+		value, err := c.readValue(field.Sheet, cell, "error reading draftrate")
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			continue
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return nil, WrapError(err, fmt.Sprintf("error parsing draftrate %s!%s", field.Sheet, cell))
+		}
+
+		if rate < 0 || rate > MaxDraftRate {
+			violations = append(violations, newViolation("draftrate", hr, field.Sheet, cell, value,
+				fmt.Sprintf("draft rate must be between 0 and %d", MaxDraftRate)))
+		}
+	}
+
+	return violations, nil
+}
+
+func (c *GameLogCmd) militaryUnitsRule() ([]Violation, error) {
+	if skipped := c.skippedRule("units", nil, []string{"units"}); skipped != nil {
+		return skipped, nil
+	}
+
+	group := c.layout.Group("units")
+	return c.checkNotNegative("units", group.Sheet, group.Columns, "released or trained more units than available")
+}
+
+func (c *GameLogCmd) manaRule() ([]Violation, error) {
+	return c.checkFieldNotNegative("mana", "mana", "cast spells for more mana than available")
+}
+
+// manaCostRule compares the mana costs written to the log with the mana deducted by
+// the sim and checks the dominion has enough mana for the costs of the log
+func (c *GameLogCmd) manaCostRule() ([]Violation, error) {
+	if skipped := c.skippedRule("mana_cost", []string{"mana", "mana_production"}, nil); skipped != nil {
+		return skipped, nil
+	}
+
+	violations := []Violation{}
+	field := c.layout.Field("mana")
+	from, to := c.ruleHours()
+
+	for hr := from; hr <= to; hr++ {
+		casts, err := c.spellCasts(hr)
+		if err != nil {
+			return nil, err
+		}
+		if len(casts) == 0 {
+			continue
+		}
+
+		deducted, available, err := c.manaDeducted(hr)
+		if err != nil {
+			return nil, err
+		}
+
+		cost, names := 0, []string{}
+		for _, cast := range casts {
+			cost += cast.Cost
+			names = append(names, cast.Spell)
+		}
+
+		cell := c.wrapHourAs(field.Column, c.hourRow(hr))
+		mana := strconv.Itoa(available - deducted)
+
+		if cost > available {
+			violations = append(violations, newViolation("mana_cost", hr, field.Sheet, cell, mana,
+				fmt.Sprintf("spells cost %d mana, only %d is available", cost, available)))
+			continue
+		}
+
+		if cost != deducted {
+			violation := newViolation("mana_cost", hr, field.Sheet, cell, mana,
+				fmt.Sprintf("%s cost %d mana with %d acres, the sim deducts %d", strings.Join(names, ", "), cost, casts[0].Land, deducted))
+			violation.Severity = SeverityWarning
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations, nil
+}
+
+func (c *GameLogCmd) populationRule() ([]Violation, error) {
+	return c.checkFieldNotNegative("population", "population_check", "population is negative")
+}
+
+func (c *GameLogCmd) buildingsRule() ([]Violation, error) {
+	if skipped := c.skippedRule("buildings", nil, []string{"buildings"}); skipped != nil {
+		return skipped, nil
+	}
+
+	group := c.layout.Group("buildings")
+	return c.checkNotNegative("buildings", group.Sheet, group.Columns, "destroyed more buildings than exist")
+}
+
+// dailyBonusRule checks the daily platinum and land are claimed once a day, claims
+// in the hours of the day before the checked range count too
+func (c *GameLogCmd) dailyBonusRule() ([]Violation, error) {
+	violations := []Violation{}
+	from, to := c.ruleHours()
+
+	bonuses := []struct {
+		field string
+		name  string
+	}{
+		{"daily_platinum", "daily platinum"},
+		{"land_bonus", "daily land"},
+	}
+
+	for _, bonus := range bonuses {
+		field := c.layout.Field(bonus.field)
+
+		for _, window := range dailyBonusWindows {
+			if window[1] < from || window[0] > to {
+				continue
+			}
+
+			claimed := 0
+			for hr := window[0]; hr <= window[1] && hr <= to; hr++ {
+				cell := c.wrapHourAs(field.Column, c.hourRow(hr))
+				value, err := c.readIntValue(field.Sheet, cell, "error reading "+bonus.name)
+				if err != nil {
+					return nil, err
+				}
+				if value == 0 {
+					continue
+				}
+
+				claimed++
+				if claimed > 1 && hr >= from {
+					violations = append(violations, newViolation("daily_bonus", hr, field.Sheet, cell, strconv.Itoa(value),
+						fmt.Sprintf("%s is already claimed in hours %d-%d", bonus.name, window[0], window[1])))
+				}
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// tradeRule checks traded resources follow the exchange rates of the bank
+func (c *GameLogCmd) tradeRule() ([]Violation, error) {
+	violations := []Violation{}
+	from, to := c.ruleHours()
+
+	for hr := from; hr <= to; hr++ {
+		sold := map[string]int{}
+		bought := map[string]int{}
+		sheet, firstCell := "", ""
+
+		for _, resource := range tradeResources {
+			field := c.layout.Field("trade_" + resource)
+			cell := c.wrapHourAs(field.Column, c.hourRow(hr))
+			value, err := c.readIntValue(field.Sheet, cell, "error reading traded "+resource)
+			if err != nil {
+				return nil, err
+			}
+			if value == 0 {
+				continue
+			}
+			if sheet == "" {
+				sheet, firstCell = field.Sheet, cell
+			}
+
+			if value < 0 {
+				sold[resource] = -value
+			} else {
+				bought[resource] = value
+			}
+		}
+
+		if len(sold) == 0 && len(bought) == 0 {
+			continue
+		}
+
+		addViolation := func(message string) {
+			violations = append(violations, newViolation("trade", hr, sheet, firstCell, formatTrade(sold, bought), message))
+		}
+
+		if len(sold) == 0 || len(bought) == 0 {
+			addViolation("trade needs both sold and bought resources")
+			continue
+		}
+
+		maxBought := 0.0
+		valid := true
+		for _, soldName := range tradeResources {
+			soldAmount, ok := sold[soldName]
+			if !ok {
+				continue
+			}
+
+			rates, ok := tradeRates[soldName]
+			if !ok {
+				addViolation(fmt.Sprintf("%s can't be sold", soldName))
+				valid = false
+				continue
+			}
+
+			best := 0.0
+			for _, boughtName := range tradeResources {
+				if _, ok := bought[boughtName]; !ok {
+					continue
+				}
+
+				rate, ok := rates[boughtName]
+				if !ok {
+					addViolation(fmt.Sprintf("%s can't be traded for %s", soldName, boughtName))
+					valid = false
+				}
+				if rate > best {
+					best = rate
+				}
+			}
+			maxBought += float64(soldAmount) * best
+		}
+
+		if !valid {
+			continue
+		}
+
+		totalBought := 0
+		for _, amount := range bought {
+			totalBought += amount
+		}
+
+		// Single exchange must match the rate, several ones can only be bounded
+		if len(sold) == 1 && len(bought) == 1 {
+			if absInt(totalBought-int(maxBought)) > 1 {
+				addViolation(fmt.Sprintf("expected %d to be bought with the exchange rate", int(maxBought)))
+			}
+		} else if float64(totalBought) > maxBought+1 {
+			addViolation(fmt.Sprintf("bought more than %d allowed by exchange rates", int(maxBought)))
+		}
+	}
+
+	return violations, nil
+}
+
+func formatTrade(sold, bought map[string]int) string {
+	parts := []string{}
+	for _, name := range tradeResources {
+		if amount, ok := sold[name]; ok {
+			parts = append(parts, fmt.Sprintf("-%d %s", amount, name))
+		}
+		if amount, ok := bought[name]; ok {
+			parts = append(parts, fmt.Sprintf("+%d %s", amount, name))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
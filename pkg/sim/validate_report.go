@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type ValidateCmd struct {
+	simPath    string
+	resultPath string
+	format     string
+	gameLog    *GameLogCmd
+}
+
+// NewValidateCmd prepares the checks of a sim without generating the log.
+func NewValidateCmd(simPath, resultPath, format string, options GameLogOptions) (*ValidateCmd, error) {
+	if err := checkFormat(format, FormatText, FormatJSON, FormatJUnit); err != nil {
+		return nil, err
+	}
+
+	gameLog, err := NewGameLog(simPath, resultPath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ValidateCmd{
+		simPath:    simPath,
+		resultPath: resultPath,
+		format:     format,
+		gameLog:    gameLog,
+	}, nil
+}
+
+// Execute writes the report, it returns an error when the sim has errors.
+func (c *ValidateCmd) Execute() error {
+	defer c.gameLog.sim.Close()
+
+	violations, err := c.gameLog.validateSim()
+	if err != nil {
+		return err
+	}
+
+	c.gameLog.printRecalcProblems()
+
+	var buf bytes.Buffer
+	if err := WriteValidationReport(&buf, c.simPath, violations, c.format); err != nil {
+		return err
+	}
+
+	if err := writeResult(c.resultPath, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if hasErrors(violations) {
+		return fmt.Errorf("%s has validation errors", c.simPath)
+	}
+
+	return nil
+}
+
+type validationReport struct {
+	Sim      string      `json:"sim"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+	Findings []Violation `json:"findings"`
+}
+
+func newValidationReport(simPath string, violations []Violation) validationReport {
+	report := validationReport{Sim: simPath, Findings: violations}
+	for _, violation := range violations {
+		if violation.Severity == SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	return report
+}
+
+// WriteValidationReport writes violations as text, json or junit xml
+func WriteValidationReport(w io.Writer, simPath string, violations []Violation, format string) error {
+	report := newValidationReport(simPath, violations)
+
+	switch format {
+	case FormatText, "":
+		for _, violation := range violations {
+			fmt.Fprintln(w, violation)
+		}
+		fmt.Fprintf(w, "%s: %d errors, %d warnings\n", simPath, report.Errors, report.Warnings)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatJUnit:
+		return writeJUnitReport(w, report)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport writes a test case per finding, so CI shows each one separately.
+// Warnings are passing test cases.
+func writeJUnitReport(w io.Writer, report validationReport) error {
+	suite := junitTestSuite{Name: report.Sim}
+
+	for _, violation := range report.Findings {
+		testCase := junitTestCase{
+			ClassName: violation.Rule,
+			Name:      violation.Location(),
+		}
+
+		if violation.Severity == SeverityError {
+			testCase.Failure = &junitFailure{
+				Message: violation.Message,
+				Type:    violation.Severity,
+				Text:    violation.String(),
+			}
+			suite.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if len(suite.Cases) == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{ClassName: "validation", Name: "sim"})
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func checkFormat(format string, formats ...string) error {
+	for _, f := range formats {
+		if format == f {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown format %q, expected one of %v", format, formats)
+}
@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var reportViolations = []Violation{
+	{Severity: SeverityError, Rule: "mana", Hour: 12, Sheet: Production, Cell: "K15", Value: "-120", Message: "cast spells for more mana than available"},
+	{Severity: SeverityWarning, Rule: "custom", Hour: 3, Sheet: Military, Cell: "AR6", Value: "100", Message: "low platinum"},
+}
+
+func TestWriteValidationReport(t *testing.T) {
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteValidationReport(&buf, "sim.xlsm", reportViolations, FormatText); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "error: hour 12 Production!K15 = \"-120\": cast spells for more mana than available\n" +
+			"warning: hour 3 Military!AR6 = \"100\": low platinum\n" +
+			"sim.xlsm: 1 errors, 1 warnings\n"
+		if buf.String() != expected {
+			t.Errorf("Incorrect result: got %q, want %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteValidationReport(&buf, "sim.xlsm", reportViolations, FormatJSON); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var report validationReport
+		if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		if report.Errors != 1 || report.Warnings != 1 || report.Findings[0].Cell != "K15" {
+			t.Errorf("Incorrect report: %+v", report)
+		}
+	})
+
+	t.Run("JUnit", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteValidationReport(&buf, "sim.xlsm", reportViolations, FormatJUnit); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var suites junitTestSuites
+		if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+			t.Fatalf("Invalid XML: %v", err)
+		}
+
+		suite := suites.Suites[0]
+		if suite.Tests != 2 || suite.Failures != 1 || suite.Cases[0].Failure == nil || suite.Cases[1].Failure != nil {
+			t.Errorf("Incorrect suite: %+v", suite)
+		}
+		if suite.Cases[0].Name != "hour 12 Production!K15" {
+			t.Errorf("Incorrect test case name: %q", suite.Cases[0].Name)
+		}
+	})
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		if err := WriteValidationReport(&bytes.Buffer{}, "sim.xlsm", nil, "yaml"); err == nil {
+			t.Errorf("Expected error, but got none")
+		}
+	})
+}
+
+func TestValidateCmdExecute(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "report.txt")
+	cmd := &ValidateCmd{
+		simPath:    "sim.xlsm",
+		resultPath: resultPath,
+		format:     FormatText,
+		gameLog: newValidateGameLog(map[string]map[string]string{
+			Military: {"Z8": "95%"},
+		}),
+	}
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "has validation errors") {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+
+	content, err := os.ReadFile(resultPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(content), "hour 5 Military!Z8") {
+		t.Errorf("Incorrect report: %q", content)
+	}
+}
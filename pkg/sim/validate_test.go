@@ -0,0 +1,201 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newValidateGameLog(simData map[string]map[string]string) *GameLogCmd {
+	glc := newMockGameLog(&SimMock{Data: simData, AllowMissing: true})
+	glc.initRules()
+	return glc
+}
+
+func TestValidateSim(t *testing.T) {
+	testCases := []struct {
+		name     string
+		simData  map[string]map[string]string
+		expected []string // rule@cell of found violations
+	}{
+		{
+			name: "Valid Sim",
+			simData: map[string]map[string]string{
+				Military:   {"Z4": "35%", "Z5": "90%", "E4": "100"},
+				Production: {"C4": "1", "C28": "1", "BC6": "-1000", "BD6": "500", "K4": "0"},
+				Explore:    {"S4": "20", "S52": "20"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "Draftrate Out Of Range",
+			simData: map[string]map[string]string{
+				Military: {"Z4": "95%", "Z5": "-1%"},
+			},
+			expected: []string{"draftrate@Z4", "draftrate@Z5"},
+		},
+		{
+			name: "Negative Values",
+			simData: map[string]map[string]string{
+				Military:     {"F10": "-5"},
+				Production:   {"K15": "-120"},
+				Population:   {"L20": "-1"},
+				Construction: {"BA7": "-2"},
+			},
+			expected: []string{"units@F10", "mana@K15", "population@L20", "buildings@BA7"},
+		},
+		{
+			name: "Daily Bonus Claimed Twice",
+			simData: map[string]map[string]string{
+				Production: {"C4": "1", "C20": "1", "C28": "1"},
+				Explore:    {"S30": "20", "S40": "20"},
+			},
+			expected: []string{"daily_bonus@C20", "daily_bonus@S40"},
+		},
+		{
+			name: "Daily Bonus After Protection",
+			simData: map[string]map[string]string{
+				Production: {"C52": "1", "C76": "1"},
+				Explore:    {"S75": "20", "S76": "20"},
+			},
+			// Hour 73 is after the 49-72 window and starts a new day
+			expected: []string{},
+		},
+		{
+			name: "Invalid Trades",
+			simData: map[string]map[string]string{
+				Production: {
+					"BC4": "-1000", "BD4": "600", // wrong rate
+					"BE5": "-100", "BC5": "50", // ore can't be sold
+					"BF6": "-10", "BC6": "20", // valid gems trade
+					"BC7": "100", // nothing sold
+				},
+			},
+			expected: []string{"trade@BC4", "trade@BC5", "trade@BC7"},
+		},
+		{
+			name: "Mana Costs",
+			simData: map[string]map[string]string{
+				Magic:      {"G4": "1", "K5": "1", "H6": "1"},
+				Explore:    {"B3": "300", "B4": "300", "B5": "300"},
+				Production: {"K3": "1000", "R4": "100", "K4": "350", "R5": "400", "K5": "0", "R6": "100", "K6": "0"},
+			},
+			// Gaia's Watch costs 2 mana per acre but the sim deducts 2.5, Harmony costs
+			// the same in both and Mining Strength costs more than the sim has
+			expected: []string{"mana_cost@K4", "mana_cost@K6"},
+		},
+		{
+			name: "Mana Deducted By The Sim",
+			simData: map[string]map[string]string{
+				Magic:      {"G4": "1"},
+				Explore:    {"B3": "300"},
+				Production: {"K3": "1000", "R4": "100", "K4": "500"},
+			},
+			// The sim deducts the 600 mana of Gaia's Watch on 300 acres from the mana column
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			glc := newValidateGameLog(tc.simData)
+
+			violations, err := glc.validateSim()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got := []string{}
+			for _, violation := range violations {
+				got = append(got, violation.Rule+"@"+violation.Cell)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Incorrect violations: got %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidateSimHourRange(t *testing.T) {
+	glc := newValidateGameLog(map[string]map[string]string{
+		Military:   {"Z4": "95%", "Z34": "95%"},
+		Production: {"K10": "-1", "C29": "1", "C33": "1", "C60": "1"},
+		Explore:    {"S30": "20", "S70": "20"},
+	})
+	glc.options = GameLogOptions{From: 30, To: 40}
+
+	violations, err := glc.validateSim()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := []string{}
+	for _, violation := range violations {
+		got = append(got, violation.Rule+"@"+violation.Cell)
+	}
+
+	// Only hours 30-40 are checked, the daily platinum of hour 26 is claimed in the same day as hour 30
+	if expected := []string{"draftrate@Z34", "daily_bonus@C33"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Incorrect violations: got %v, want %v", got, expected)
+	}
+}
+
+func TestDailyBonusWindows(t *testing.T) {
+	for hr := 1; hr <= LastHour; hr++ {
+		count := 0
+		for _, window := range dailyBonusWindows {
+			if hr >= window[0] && hr <= window[1] {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("Hour %d is in %d daily bonus windows", hr, count)
+		}
+	}
+}
+
+func TestValidateSimCustomRule(t *testing.T) {
+	glc := newValidateGameLog(map[string]map[string]string{})
+	glc.AddRule(func() ([]Violation, error) {
+		return []Violation{{Severity: SeverityWarning, Rule: "custom"}}, nil
+	})
+
+	violations, err := glc.validateSim()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(violations) != 1 || violations[0].Rule != "custom" || hasErrors(violations) {
+		t.Errorf("Incorrect violations: %+v", violations)
+	}
+}
+
+func TestValidateSimOptionalLayout(t *testing.T) {
+	glc := newValidateGameLog(map[string]map[string]string{
+		Military:     {"F10": "-5"},
+		Construction: {"BA7": "-2"},
+	})
+	delete(glc.layout.Groups, "units")
+	delete(glc.layout.Groups, "buildings")
+	delete(glc.layout.Fields, "mana_production")
+
+	if err := glc.layout.validate(); err != nil {
+		t.Fatalf("Layout without the validation groups should load: %v", err)
+	}
+
+	violations, err := glc.validateSim()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := []string{}
+	for _, violation := range violations {
+		got = append(got, violation.Rule+"@"+violation.Location())
+	}
+	if expected := []string{"units@layout", "mana_cost@layout", "buildings@layout"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Incorrect violations: got %v, want %v", got, expected)
+	}
+	if hasErrors(violations) {
+		t.Errorf("Skipped rules should only warn: %v", violations)
+	}
+}
@@ -0,0 +1,222 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	WatchInterval = 500 * time.Millisecond
+	// Excel saves to a temporary file and renames it, the sim is read once it stops changing
+	WatchDebounce = 2 * time.Second
+)
+
+// WatchCmd regenerates the log every time the sim is saved
+type WatchCmd struct {
+	simPath    string
+	resultPath string
+	options    GameLogOptions
+	interval   time.Duration
+	debounce   time.Duration
+	out        io.Writer
+}
+
+// simState tells if the sim file was changed
+type simState struct {
+	modTime time.Time
+	size    int64
+}
+
+func NewWatchCmd(simPath, resultPath string, options GameLogOptions) (*WatchCmd, error) {
+	if resultPath == "" || resultPath == "std" {
+		return nil, fmt.Errorf("-watch needs a -result file")
+	}
+
+	return &WatchCmd{
+		simPath:    simPath,
+		resultPath: resultPath,
+		options:    options,
+		interval:   WatchInterval,
+		debounce:   WatchDebounce,
+		out:        os.Stdout,
+	}, nil
+}
+
+// Execute watches the sim until the command is interrupted
+func (c *WatchCmd) Execute() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return c.Run(ctx)
+}
+
+func (c *WatchCmd) Run(ctx context.Context) error {
+	state, err := readSimState(c.simPath)
+	if err != nil {
+		return WrapError(err, "error on watching sim file")
+	}
+
+	hours := c.generate(nil)
+
+	fmt.Fprintf(c.out, "Watching %s for changes, press Ctrl+C to stop\n", c.simPath)
+
+	for {
+		state, err = c.waitForChange(ctx, state)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		fmt.Fprintf(c.out, "\n%s changed at %s\n", c.simPath, state.modTime.Format(time.TimeOnly))
+		hours = c.generate(hours)
+	}
+}
+
+// generate writes the log and prints its difference with the previous one,
+// on errors it keeps the previous hours to compare with the next save
+func (c *WatchCmd) generate(previous []*HourLog) []*HourLog {
+	gameLog, err := NewGameLog(c.simPath, c.resultPath, c.options)
+	if err != nil {
+		fmt.Fprintln(c.out, err)
+		return previous
+	}
+	defer gameLog.Close()
+
+	result, err := gameLog.Generate()
+	if result != nil {
+		for _, violation := range result.Violations {
+			fmt.Fprintln(c.out, violation)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(c.out, err)
+		return previous
+	}
+	if result.HourErr != nil {
+		fmt.Fprintln(c.out, result.HourErr)
+	}
+
+	if err := os.WriteFile(c.resultPath, []byte(result.Log), 0644); err != nil {
+		fmt.Fprintln(c.out, "Error writing to file:", err)
+		return previous
+	}
+	fmt.Fprintf(c.out, "Successfully wrote result to %s\n", c.resultPath)
+
+	if previous != nil {
+		fmt.Fprint(c.out, diffHours(previous, result.Hours))
+	}
+
+	return result.Hours
+}
+
+// waitForChange polls the sim until it's changed and then stays the same for the debounce time
+func (c *WatchCmd) waitForChange(ctx context.Context, last simState) (simState, error) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	changed := false
+	var changedAt time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+
+		// The file can be missing for a moment while it's being saved
+		state, err := readSimState(c.simPath)
+		if err != nil {
+			changed, changedAt = true, time.Now()
+			continue
+		}
+
+		if state != last {
+			last = state
+			changed, changedAt = true, time.Now()
+			continue
+		}
+
+		if changed && time.Since(changedAt) >= c.debounce {
+			return state, nil
+		}
+	}
+}
+
+func readSimState(simPath string) (simState, error) {
+	info, err := os.Stat(simPath)
+	if err != nil {
+		return simState{}, err
+	}
+
+	return simState{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// diffHours lists actions removed and added in every changed hour
+func diffHours(previous, current []*HourLog) string {
+	previousLines := hourLines(previous)
+	currentLines := hourLines(current)
+
+	var sb strings.Builder
+	for hr := 1; hr <= LastHour; hr++ {
+		removed := subtractLines(previousLines[hr], currentLines[hr])
+		added := subtractLines(currentLines[hr], previousLines[hr])
+		if len(removed) == 0 && len(added) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("Hour %d:\n", hr))
+		for _, line := range removed {
+			sb.WriteString("- " + line + "\n")
+		}
+		for _, line := range added {
+			sb.WriteString("+ " + line + "\n")
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "No actions changed.\n"
+	}
+
+	return sb.String()
+}
+
+// hourLines returns rendered actions by hour
+func hourLines(hours []*HourLog) map[int][]string {
+	lines := map[int][]string{}
+
+	for _, hour := range hours {
+		events := strings.TrimRight(renderEvents(hour.Events), "\n")
+		if events != "" {
+			lines[hour.Hour] = strings.Split(events, "\n")
+		}
+	}
+
+	return lines
+}
+
+// subtractLines returns lines missing in other, repeated lines are counted
+func subtractLines(lines, other []string) []string {
+	counts := map[string]int{}
+	for _, line := range other {
+		counts[line]++
+	}
+
+	result := []string{}
+	for _, line := range lines {
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return result
+}
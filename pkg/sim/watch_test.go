@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffHours(t *testing.T) {
+	previous := []*HourLog{
+		{Hour: 1, Events: []Event{DraftRateChanged{Rate: "35"}, TechUnlocked{Tech: "Rationing"}}},
+		{Hour: 3, Events: []Event{UnitsReleased{Units: []Amount{{"Satyr", 3}}}}},
+	}
+	current := []*HourLog{
+		{Hour: 1, Events: []Event{DraftRateChanged{Rate: "35"}, TechUnlocked{Tech: "Rationing"}}},
+		{Hour: 2, Events: []Event{DailyPlatinum{Platinum: 100}}},
+		{Hour: 3, Events: []Event{UnitsReleased{Units: []Amount{{"Satyr", 5}}}}},
+	}
+
+	expected := "Hour 2:\n" +
+		"+ You have been awarded with 100 platinum.\n" +
+		"Hour 3:\n" +
+		"- You successfully released 3 Satyr.\n" +
+		"+ You successfully released 5 Satyr.\n"
+
+	if result := diffHours(previous, current); result != expected {
+		t.Errorf("Incorrect diff:\n%s\nwant:\n%s", result, expected)
+	}
+
+	if result := diffHours(current, current); result != "No actions changed.\n" {
+		t.Errorf("Incorrect diff of the same hours: %q", result)
+	}
+}
+
+func TestWatchWaitForChange(t *testing.T) {
+	simPath := filepath.Join(t.TempDir(), "sim.xlsm")
+	if err := os.WriteFile(simPath, []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &WatchCmd{simPath: simPath, interval: 5 * time.Millisecond, debounce: 50 * time.Millisecond}
+	state, err := readSimState(simPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Temporary file dance of Excel: the sim is removed and written again
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		os.Remove(simPath)
+		time.Sleep(20 * time.Millisecond)
+		os.WriteFile(simPath, []byte("version 2"), 0644)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	started := time.Now()
+	changed, err := cmd.waitForChange(ctx, state)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed.size != int64(len("version 2")) {
+		t.Errorf("Expected the saved sim state, got %+v", changed)
+	}
+	if time.Since(started) < 90*time.Millisecond {
+		t.Errorf("Change was reported before the sim stopped changing")
+	}
+
+	// Nothing changes until the context is done
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := cmd.waitForChange(ctx, changed); err == nil {
+		t.Errorf("Expected context error without changes")
+	}
+}